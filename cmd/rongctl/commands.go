@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/rongcloud/server-sdk-go/v4/sdk"
+)
+
+// command One action of a subsystem
+type command struct {
+	usage string // Arguments, shown in the help output
+	args  int    // Minimum number of arguments
+	run   func(rc *sdk.RongCloud, args []string) (interface{}, error)
+}
+
+// ok is printed for actions that return nothing but an error.
+var ok = map[string]string{"status": "ok"}
+
+var commands = map[string]map[string]command{
+	"user": {
+		"register": {"<userId> <name> [portraitUri]", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.UserRegister(a[0], a[1], opt(a, 2))
+		}},
+		"update": {"<userId> <name> [portraitUri]", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.UserUpdate(a[0], a[1], opt(a, 2))
+		}},
+		"info": {"<userId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.UserInfoGet(a[0])
+		}},
+		"online": {"<userId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			status, err := rc.OnlineStatusCheck(a[0])
+			return map[string]interface{}{"userId": a[0], "online": status == 1}, err
+		}},
+		"block": {"<userId> <minutes>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			minutes, err := strconv.ParseUint(a[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("minutes: %v", err)
+			}
+			return ok, rc.BlockAdd(a[0], minutes)
+		}},
+		"unblock": {"<userId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.BlockRemove(a[0])
+		}},
+		"blocked": {"", 0, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			res, err := rc.BlockGetList()
+			return res.Users, err
+		}},
+		"deactivate": {"<userId>...", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.UserDeactivate(a)
+		}},
+		"reactivate": {"<userId>...", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.UserReactivate(a)
+		}},
+	},
+	"group": {
+		"create": {"<groupId> <name> <userId>...", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupCreate(a[0], a[1], a[2:])
+		}},
+		"join": {"<groupId> <name> <userId>...", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupJoin(a[0], a[1], a[2:])
+		}},
+		"quit": {"<groupId> <userId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupQuit(a[1:], a[0])
+		}},
+		"dismiss": {"<groupId> <userId>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupDismiss(a[0], a[1])
+		}},
+		"get": {"<groupId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupGet(a[0])
+		}},
+	},
+	"entrustgroup": {
+		"create": {"<groupId> <name> <owner> [userId...]", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupCreate(sdk.CreateEntrustGroupModel{GroupId: a[0], Name: a[1], Owner: a[2], UserIds: a[3:]})
+		}},
+		"dismiss": {"<groupId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupDismiss(a[0])
+		}},
+		"join": {"<groupId> <userId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupJoin(a[0], a[1:]...)
+		}},
+		"quit": {"<groupId> <userId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupQuit(sdk.QuitEntrustGroupModel{GroupId: a[0], UserIds: a[1:]})
+		}},
+		"profiles": {"<groupId>...", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupQueryProfiles(a...)
+		}},
+		"members": {"<groupId> [pageToken]", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupPagingQueryMembers(sdk.PagingQueryMembersModel{GroupId: a[0], PageToken: opt(a, 1), Size: 100})
+		}},
+		"kick-all": {"<userId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.EntrustGroupKickOutAllGroups(a[0])
+		}},
+	},
+	"ultragroup": {
+		"create": {"<userId> <groupId> <name>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().Create(a[0], a[1], a[2]))
+		}},
+		"dismiss": {"<groupId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().Dismiss(a[0]))
+		}},
+		"join": {"<userId> <groupId>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().Join(a[0], a[1]))
+		}},
+		"quit": {"<userId> <groupId>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().Quit(a[0], a[1]))
+		}},
+		"channels": {"<groupId> [page]", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			page, err := optInt(a, 1, 1)
+			if err != nil {
+				return nil, err
+			}
+			channels, _, err := rc.UltraGroup().ChannelQuery(a[0], page, 100)
+			return channels, err
+		}},
+		"mute": {"<groupId> <busChannel> <userId>...", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().MuteMembersAdd(a[0], a[1], a[2:]...))
+		}},
+		"unmute": {"<groupId> <busChannel> <userId>...", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return requestIdResult(rc.UltraGroup().MuteMembersRemove(a[0], a[1], a[2:]...))
+		}},
+	},
+	"chatroom": {
+		"create": {"<chatroomId> <name>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.ChatRoomCreate(a[0], a[1])
+		}},
+		"destroy": {"<chatroomId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.ChatRoomDestroy(a[0])
+		}},
+		"query": {"<chatroomId>...", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.ChatRoomQuery(a)
+		}},
+		"members": {"<chatroomId> [count]", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			count, err := optInt(a, 1, 500)
+			if err != nil {
+				return nil, err
+			}
+			return rc.ChatRoomGet(a[0], count, 1)
+		}},
+		"mute": {"<chatroomId> <minutes> <userId>...", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			minutes, err := strconv.ParseUint(a[1], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("minutes: %v", err)
+			}
+			return ok, rc.ChatRoomGagAdd(a[0], a[2:], uint(minutes))
+		}},
+		"unmute": {"<chatroomId> <userId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.ChatRoomGagRemove(a[0], a[1:])
+		}},
+		"muted": {"<chatroomId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.ChatRoomGagGetList(a[0])
+		}},
+	},
+	"message": {
+		"private": {"<fromUserId> <toUserId> <text>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.PrivateSend(a[0], []string{a[1]}, "RC:TxtMsg", &sdk.TXTMsg{Content: a[2]}, "", "", 0, 0, 1, 0, 0)
+		}},
+		"group": {"<fromUserId> <groupId> <text>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.GroupSend(a[0], []string{a[1]}, nil, "RC:TxtMsg", &sdk.TXTMsg{Content: a[2]}, "", "", 1, 0)
+		}},
+		"system": {"<fromUserId> <toUserId> <text>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.SystemSend(a[0], []string{a[1]}, "RC:TxtMsg", &sdk.TXTMsg{Content: a[2]}, "", "", 0, 1)
+		}},
+		"history-private": {"<userId> <targetId> <startTime> <endTime>", 4, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			model, err := historyModel(a)
+			if err != nil {
+				return nil, err
+			}
+			res, err := rc.GetPrivateHistoryMessage(model)
+			return res.Data, err
+		}},
+		"history-group": {"<userId> <groupId> <startTime> <endTime>", 4, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			model, err := historyModel(a)
+			if err != nil {
+				return nil, err
+			}
+			res, err := rc.GetGroupHistoryMessage(model)
+			return res.Data, err
+		}},
+	},
+	"push": {
+		"user": {"<content> <userId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.PushUser(&sdk.PushNotification{PushContent: a[0]}, a[1:]...)
+		}},
+		"custom": {"<file.json>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			body, err := ioutil.ReadFile(a[0])
+			if err != nil {
+				return nil, err
+			}
+			res, err := rc.PushCustom(body)
+			return json.RawMessage(res), err
+		}},
+	},
+	"friend": {
+		"add": {"<userId> <targetId>", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			optType := sdk.FriendOptTypeDirect
+			return rc.FriendAdd(sdk.FriendModel{UserId: a[0], TargetId: a[1], OptType: &optType})
+		}},
+		"delete": {"<userId> <targetId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.FriendDelete(a[0], a[1:]...)
+		}},
+		"list": {"<userId>", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			graph, err := rc.FriendGraphGet(a[0])
+			return graph[a[0]], err
+		}},
+		"check": {"<userId> <targetId>...", 2, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.FriendCheckFriends(a[0], a[1:]...)
+		}},
+	},
+	"sensitive": {
+		"add": {"<keyword> [replace]", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			if replace := opt(a, 1); replace != "" {
+				return ok, rc.SensitiveAdd(a[0], replace, 0)
+			}
+			return ok, rc.SensitiveAdd(a[0], "*", 1)
+		}},
+		"list": {"", 0, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return rc.SensitiveGetList()
+		}},
+		"remove": {"<keyword>...", 1, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			return ok, rc.SensitiveRemove(a)
+		}},
+	},
+	"conversation": {
+		"mute": {"<type> <userId> <targetId>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			t, err := conversationType(a[0])
+			if err != nil {
+				return nil, err
+			}
+			return ok, rc.ConversationMute(t, a[1], a[2])
+		}},
+		"unmute": {"<type> <userId> <targetId>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			t, err := conversationType(a[0])
+			if err != nil {
+				return nil, err
+			}
+			return ok, rc.ConversationUnmute(t, a[1], a[2])
+		}},
+		"get": {"<type> <userId> <targetId>", 3, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			t, err := conversationType(a[0])
+			if err != nil {
+				return nil, err
+			}
+			muted, err := rc.ConversationGet(t, a[1], a[2])
+			return map[string]interface{}{"targetId": a[2], "muted": muted == 1}, err
+		}},
+		"top": {"<type> <userId> <targetId> <true|false>", 4, func(rc *sdk.RongCloud, a []string) (interface{}, error) {
+			t, err := conversationType(a[0])
+			if err != nil {
+				return nil, err
+			}
+			return ok, rc.ConversationTop(t, a[1], a[2], a[3])
+		}},
+	},
+}
+
+// lookup returns the command of a subsystem action.
+func lookup(subsystem, action string) (command, error) {
+	actions, found := commands[subsystem]
+	if !found {
+		return command{}, fmt.Errorf("unknown subsystem %q, run rongctl -h for the list of commands", subsystem)
+	}
+	cmd, found := actions[action]
+	if !found {
+		return command{}, fmt.Errorf("unknown action %q for %s, run rongctl -h for the list of commands", action, subsystem)
+	}
+	return cmd, nil
+}
+
+// check verifies the number of arguments.
+func (c command) check(args []string) error {
+	if len(args) < c.args {
+		return fmt.Errorf("expected arguments: %s", c.usage)
+	}
+	return nil
+}
+
+func opt(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func optInt(args []string, i, def int) (int, error) {
+	if i >= len(args) {
+		return def, nil
+	}
+	return strconv.Atoi(args[i])
+}
+
+func requestIdResult(requestId string, err error) (interface{}, error) {
+	return map[string]string{"status": "ok", "requestId": requestId}, err
+}
+
+func historyModel(a []string) (sdk.QueryHistoryMessageModel, error) {
+	start, err := strconv.ParseInt(a[2], 10, 64)
+	if err != nil {
+		return sdk.QueryHistoryMessageModel{}, fmt.Errorf("startTime: %v", err)
+	}
+	end, err := strconv.ParseInt(a[3], 10, 64)
+	if err != nil {
+		return sdk.QueryHistoryMessageModel{}, fmt.Errorf("endTime: %v", err)
+	}
+	return sdk.QueryHistoryMessageModel{UserID: a[0], TargetID: a[1], StartTime: start, EndTime: end, IncludeStart: true}, nil
+}
+
+// conversationType accepts private, group, system, ultragroup or the numeric conversation type.
+func conversationType(s string) (sdk.ConversationType, error) {
+	switch strings.ToLower(s) {
+	case "private":
+		return sdk.ConversationTypePrivate, nil
+	case "group":
+		return sdk.ConversationTypeGroup, nil
+	case "system":
+		return sdk.ConversationTypeSystem, nil
+	case "ultragroup":
+		return sdk.ConversationTypeUG, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("unknown conversation type %q", s)
+	}
+	return sdk.ConversationType(n), nil
+}
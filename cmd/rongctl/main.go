@@ -0,0 +1,178 @@
+// Command rongctl calls the RongCloud server API from the command line.
+//
+// Usage:
+//
+//	rongctl [flags] <subsystem> <action> [args...]
+//
+// Credentials are read with sdk.LoadConfig: from the YAML or JSON config file passed with -config, overridden by
+// the RONGCLOUD_* environment variables. -profile selects a profile of the file, and -region replaces its region.
+// With -file every CSV record of the file is appended to the arguments and the action runs once per record.
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/rongcloud/server-sdk-go/v4/sdk"
+)
+
+// batchResult Outcome of one record of a -file run
+type batchResult struct {
+	Args   []string    `json:"args"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("rongctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "YAML or JSON config file, see sdk.LoadConfig")
+	profile := flags.String("profile", "", "Profile of the config file (default $RONGCLOUD_PROFILE or the default profile)")
+	region := flags.String("region", "", "API region: bj, sg, sg_b, na or sau, replaces the region of the profile")
+	output := flags.String("output", "json", "Output format: json or table")
+	file := flags.String("file", "", "CSV file, the action runs once per record with the record appended to the arguments")
+	flags.Usage = func() { usage(flags, stderr) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 2 {
+		usage(flags, stderr)
+		return 2
+	}
+	cmd, err := lookup(flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *output != "json" && *output != "table" {
+		fmt.Fprintln(stderr, "unknown output format:", *output)
+		return 2
+	}
+
+	if *region != "" {
+		if _, ok := sdk.RegionByName(*region); !ok {
+			fmt.Fprintln(stderr, "unknown region:", *region)
+			return 2
+		}
+	}
+	rc, err := newClient(*configPath, *profile, *region)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	base := flags.Args()[2:]
+	if *file == "" {
+		if err := cmd.check(base); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		result, err := cmd.run(rc, base)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if err := write(stdout, *output, result); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	records, err := readBatch(*file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	status := 0
+	results := make([]batchResult, 0, len(records))
+	for _, record := range records {
+		args := append(append([]string(nil), base...), record...)
+		res := batchResult{Args: args}
+		if err := cmd.check(args); err != nil {
+			res.Error = err.Error()
+		} else if res.Result, err = cmd.run(rc, args); err != nil {
+			res.Error = err.Error()
+		}
+		if res.Error != "" {
+			status = 1
+		}
+		results = append(results, res)
+	}
+	if err := write(stdout, *output, results); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return status
+}
+
+// newClient creates the client of a profile loaded with sdk.LoadConfig, with region replacing its region or domains.
+func newClient(configPath, profile, region string) (*sdk.RongCloud, error) {
+	conf, err := sdk.LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	name := profile
+	if name == "" {
+		name = os.Getenv("RONGCLOUD_PROFILE")
+	}
+	if name == "" {
+		name = conf.Default
+	}
+	if name == "" {
+		name = sdk.CONFIG_DEFAULT_PROFILE
+	}
+	if region != "" {
+		p := conf.Profiles[name]
+		p.Region, p.PrimaryDomain, p.BackupDomain = region, "", ""
+		if conf.Profiles == nil {
+			conf.Profiles = map[string]sdk.ClientConfig{}
+		}
+		conf.Profiles[name] = p
+	}
+	return conf.Client(name)
+}
+
+// readBatch reads the CSV records of a batch file, skipping empty lines and lines starting with #.
+func readBatch(path string) ([][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	return r.ReadAll()
+}
+
+func usage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: rongctl [flags] <subsystem> <action> [args...]")
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nCommands:")
+	subsystems := make([]string, 0, len(commands))
+	for name := range commands {
+		subsystems = append(subsystems, name)
+	}
+	sort.Strings(subsystems)
+	for _, subsystem := range subsystems {
+		actions := make([]string, 0, len(commands[subsystem]))
+		for name := range commands[subsystem] {
+			actions = append(actions, name)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			fmt.Fprintf(w, "  %s %s %s\n", subsystem, action, commands[subsystem][action].usage)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"user"}, &stdout, &stderr); code != 2 {
+		t.Errorf("unexpected exit code %d", code)
+	}
+	if code := run([]string{"nothing", "here"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "unknown subsystem") {
+		t.Errorf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"-region", "mars", "user", "info", "u01"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "unknown region") {
+		t.Errorf("unexpected exit code %d: %s", code, stderr.String())
+	}
+}
+
+func TestWrite_Table(t *testing.T) {
+	var out bytes.Buffer
+	users := map[string]interface{}{"users": []map[string]string{{"userId": "u01", "blockEndTime": "2024-01-01 00:00:00"}}}
+	if err := write(&out, "table", users); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "BLOCKENDTIME") || !strings.HasSuffix(lines[1], "u01") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := write(&out, "table", map[string]interface{}{"status": "ok", "requestId": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "requestId  r1") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+}
+
+func TestReadBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rongctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "users.csv")
+	if err := ioutil.WriteFile(path, []byte("# userId,name\nu01, Alice\n\nu02,Bob,https://example.com/b.png\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := readBatch(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"u01", "Alice"}, {"u02", "Bob", "https://example.com/b.png"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
+
+func TestConversationType(t *testing.T) {
+	for in, want := range map[string]int{"private": 1, "GROUP": 3, "system": 6, "ultragroup": 10, "4": 4} {
+		got, err := conversationType(in)
+		if err != nil || int(got) != want {
+			t.Errorf("%s: got %d %v", in, got, err)
+		}
+	}
+	if _, err := conversationType("channel"); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
+
+func TestRun_UserInfo(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-output", "table", "user", "info", "u01"}, &stdout, &stderr)
+	t.Log(code, stdout.String(), stderr.String())
+}
+
+func TestRun_Profile(t *testing.T) {
+	var appKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appKey = r.Header.Get("App-Key")
+		_, _ = w.Write([]byte(`{"code": 200, "userName": "Alice"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "rongctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rongcloud.yaml")
+	conf := "default: prod\nprofiles:\n  prod:\n    appKey: prod-key\n    appSecret: secret\n    region: sg\n" +
+		"  test:\n    appKey: test-key\n    appSecret: secret\n    primaryDomain: " + server.URL + "\n"
+	if err := ioutil.WriteFile(path, []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-config", path, "-profile", "test", "user", "info", "u01"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("unexpected exit code %d: %s", code, stderr.String())
+	}
+	if appKey != "test-key" || !strings.Contains(stdout.String(), "Alice") {
+		t.Errorf("App-Key = %s, output = %s", appKey, stdout.String())
+	}
+	stderr.Reset()
+	if code := run([]string{"-config", path, "-profile", "staging", "user", "info", "u01"}, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "staging") {
+		t.Errorf("unexpected exit code %d: %s", code, stderr.String())
+	}
+}
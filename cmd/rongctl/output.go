@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// write prints a result as indented JSON or as a table.
+func write(w io.Writer, format string, v interface{}) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	// Tables are built from the JSON form, so field names match the API documentation.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch value := generic.(type) {
+	case []interface{}:
+		writeRows(tw, value)
+	case map[string]interface{}:
+		// A result holding a single list, such as {"users": [...]}, is shown as that list.
+		if list, ok := singleList(value); ok {
+			writeRows(tw, list)
+			break
+		}
+		keys := sortedKeys(value)
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\n", key, cell(value[key]))
+		}
+	default:
+		fmt.Fprintln(tw, cell(value))
+	}
+	return tw.Flush()
+}
+
+// writeRows prints a list of objects with one column per field, or a list of values with one row per value.
+func writeRows(w io.Writer, rows []interface{}) {
+	columns := map[string]interface{}{}
+	for _, row := range rows {
+		object, ok := row.(map[string]interface{})
+		if !ok {
+			for _, row := range rows {
+				fmt.Fprintln(w, cell(row))
+			}
+			return
+		}
+		for key := range object {
+			columns[key] = true
+		}
+	}
+	keys := sortedKeys(columns)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(keys, "\t")))
+	for _, row := range rows {
+		object := row.(map[string]interface{})
+		cells := make([]string, len(keys))
+		for i, key := range keys {
+			cells[i] = cell(object[key])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+}
+
+func singleList(m map[string]interface{}) ([]interface{}, bool) {
+	var list []interface{}
+	found := false
+	for key, value := range m {
+		if key == "code" {
+			continue
+		}
+		l, ok := value.([]interface{})
+		if !ok || found {
+			return nil, false
+		}
+		list, found = l, true
+	}
+	return list, found
+}
+
+// cell formats a value for a table cell, nested values as compact JSON.
+func cell(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
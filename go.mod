@@ -3,6 +3,7 @@ module github.com/rongcloud/server-sdk-go/v4
 require (
 	github.com/astaxie/beego v1.12.0
 	github.com/google/uuid v1.3.0
+	gopkg.in/yaml.v2 v2.2.1
 )
 
 go 1.13
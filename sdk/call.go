@@ -0,0 +1,113 @@
+// Generic signed API call for endpoints the SDK does not wrap yet
+
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/httplib"
+)
+
+const (
+	// APIVersionV1 Endpoints signed with fillHeader that report errors as CodeResult, such as /user/getToken.json
+	APIVersionV1 = 1
+	// APIVersionV2 Endpoints signed with fillHeaderV2 that report errors as CodeResultV2, such as /v3/ultragroups
+	APIVersionV2 = 2
+)
+
+// APIRequest A request to any RongCloud server API endpoint
+type APIRequest struct {
+	Method  string        // HTTP method, defaults to POST
+	Path    string        // Endpoint path relative to the API domain, such as "/user/getToken.json"
+	Version int           // APIVersionV1 (default) or APIVersionV2
+	Form    url.Values    // Form parameters, sent in the body for POST and PUT and in the query otherwise
+	Body    interface{}   // JSON body, encoded with encoding/json. Form must be empty when Body is set
+	Timeout time.Duration // Request timeout, defaults to the timeout of the RongCloud object
+}
+
+// Call Sends a request to an endpoint the SDK has no wrapper for. The request is signed like every other request,
+// uses the shared transport, switches to the backup domain on network errors and 5xx responses,
+// and decodes error codes into CodeResult or CodeResultV2 depending on the version.
+/*
+*@param  req: Endpoint and parameters.
+*@param  result: Pointer the JSON response is decoded into, nil to ignore the response. *[]byte receives the raw body.
+*
+*@return string: X-Request-Id of the request.
+*@return error
+ */
+func (rc *RongCloud) Call(req APIRequest, result interface{}) (string, error) {
+	if req.Path == "" {
+		return "", RCErrorNew(1002, "Paramer 'path' is required")
+	}
+	if req.Body != nil && len(req.Form) > 0 {
+		return "", RCErrorNew(1002, "Paramer 'form' and 'body' cannot be used together")
+	}
+	if req.Version == 0 {
+		req.Version = APIVersionV1
+	}
+	if req.Version != APIVersionV1 && req.Version != APIVersionV2 {
+		return "", RCErrorNew(1002, "Paramer 'version' was wrong")
+	}
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	path := req.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = time.Second * rc.timeout
+	}
+
+	hasBody := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+	rawURL := rc.rongCloudURI + path
+	if !hasBody && len(req.Form) > 0 {
+		rawURL += "?" + req.Form.Encode()
+	}
+	r := httplib.NewBeegoRequest(rawURL, method)
+	r.SetTimeout(timeout, timeout)
+
+	var requestId string
+	if req.Version == APIVersionV2 {
+		requestId = rc.fillHeaderV2(r)
+	} else {
+		requestId = rc.fillHeader(r)
+	}
+	if hasBody {
+		for key, values := range req.Form {
+			for _, value := range values {
+				r.Param(key, value)
+			}
+		}
+	}
+	if req.Body != nil {
+		if _, err := r.JSONBody(req.Body); err != nil {
+			return requestId, err
+		}
+	}
+
+	var body []byte
+	var err error
+	if req.Version == APIVersionV2 {
+		body, err = rc.doV2(r)
+	} else {
+		body, err = rc.do(r)
+	}
+	if err != nil {
+		return requestId, err
+	}
+	switch out := result.(type) {
+	case nil:
+		return requestId, nil
+	case *[]byte:
+		*out = body
+		return requestId, nil
+	}
+	return requestId, json.Unmarshal(body, result)
+}
@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestRongCloud_CallLocal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Signature") == "" || r.Header.Get("App-Key") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/user/info.json":
+			_ = r.ParseForm()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "userName": r.Form.Get("userId")})
+		case "/v3/things":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 10000, "data": body["name"]})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 1004, "errorMessage": "not found"})
+		}
+	}))
+	defer server.Close()
+
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	uri := rc.rongCloudURI
+	rc.PrivateURI(server.URL)
+	defer rc.PrivateURI(uri)
+
+	info := struct {
+		UserName string `json:"userName"`
+	}{}
+	requestId, err := rc.Call(APIRequest{Path: "user/info.json", Form: url.Values{"userId": {"u01"}}}, &info)
+	if err != nil || info.UserName != "u01" || requestId == "" {
+		t.Errorf("unexpected v1 result: %+v %s %v", info, requestId, err)
+	}
+
+	var raw []byte
+	if _, err = rc.Call(APIRequest{Path: "/v3/things", Version: APIVersionV2, Body: map[string]string{"name": "x"}}, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "{\"code\":10000,\"data\":\"x\"}\n" {
+		t.Errorf("unexpected v2 body: %s", raw)
+	}
+
+	if _, err = rc.Call(APIRequest{Path: "/missing.json"}, nil); err == nil || err.(CodeResult).Code != 1004 {
+		t.Errorf("expected CodeResult 1004, got %v", err)
+	}
+	if _, err = rc.Call(APIRequest{Path: "/user/info.json", Form: url.Values{"a": {"b"}}, Body: 1}, nil); err == nil {
+		t.Error("expected error for form and body together")
+	}
+}
+
+func TestRongCloud_Call(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	info := UserInfoResult{}
+	if _, err := rc.Call(APIRequest{Path: "/user/info.json", Form: url.Values{"userId": {"u01"}}}, &info); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Logf("%+v", info)
+}
@@ -0,0 +1,326 @@
+// Circuit breaker that fails requests at once while the API keeps failing
+
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CIRCUIT_FAILURE_THRESHOLD Default number of consecutive failures that open a circuit
+	CIRCUIT_FAILURE_THRESHOLD = 5
+	// CIRCUIT_OPEN_TIMEOUT Default time a circuit stays open before trial requests are let through
+	CIRCUIT_OPEN_TIMEOUT = 30 * time.Second
+	// CIRCUIT_HALF_OPEN_REQUESTS Default number of trial requests that must succeed to close a circuit
+	CIRCUIT_HALF_OPEN_REQUESTS = 1
+)
+
+// CircuitState State of a circuit
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Requests pass, failures are counted
+	CircuitOpen                         // Requests fail at once with *CircuitOpenError
+	CircuitHalfOpen                     // A limited number of trial requests pass
+)
+
+// String returns "closed", "open" or "half-open"
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitOpenError Returned instead of sending a request while its circuit is open. The SDK wraps it in a
+// *url.Error, use errors.As to detect it.
+type CircuitOpenError struct {
+	Name  string    // Circuit name: the domain and the endpoint family, such as "api.rong-api.com message"
+	Until time.Time // When trial requests will be let through
+}
+
+// Error implements error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit %s is open until %s", e.Name, e.Until.Format(time.RFC3339))
+}
+
+// CircuitBreaker Keeps one circuit per API domain and endpoint family. A circuit opens after a number of consecutive
+// failures, where a failure is a network error or a 5xx response. While open, requests fail at once; after the open
+// timeout trial requests are let through, and the circuit closes when they succeed or opens again when one fails.
+type CircuitBreaker struct {
+	threshold     int
+	openTimeout   time.Duration
+	trials        int
+	family        func(path string) string
+	onStateChange func(name string, from, to CircuitState)
+	now           func() time.Time
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int       // Consecutive failures while closed
+	openedAt  time.Time // When the circuit last opened
+	inFlight  int       // Trial requests running while half-open
+	successes int       // Trial requests that succeeded while half-open
+}
+
+// circuitTransition A state change, reported after the lock is released
+type circuitTransition struct {
+	name     string
+	from, to CircuitState
+}
+
+// CircuitBreakerOption Functional option of NewCircuitBreaker
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithCircuitFailureThreshold sets the number of consecutive failures that open a circuit, default CIRCUIT_FAILURE_THRESHOLD
+func WithCircuitFailureThreshold(n int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.threshold = n
+	}
+}
+
+// WithCircuitOpenTimeout sets how long a circuit stays open before trial requests, default CIRCUIT_OPEN_TIMEOUT
+func WithCircuitOpenTimeout(d time.Duration) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.openTimeout = d
+	}
+}
+
+// WithCircuitHalfOpenRequests sets the number of trial requests that must succeed to close a circuit, default CIRCUIT_HALF_OPEN_REQUESTS
+func WithCircuitHalfOpenRequests(n int) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.trials = n
+	}
+}
+
+// WithCircuitFamily sets how a request path maps to its endpoint family, default CircuitFamily
+func WithCircuitFamily(family func(path string) string) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.family = family
+	}
+}
+
+// WithCircuitStateChange sets a function called after every state change of a circuit
+func WithCircuitStateChange(onStateChange func(name string, from, to CircuitState)) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onStateChange = onStateChange
+	}
+}
+
+// NewCircuitBreaker creates a CircuitBreaker. Plug it in with WithCircuitBreaker, or wrap any transport with Transport.
+func NewCircuitBreaker(options ...CircuitBreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		threshold:   CIRCUIT_FAILURE_THRESHOLD,
+		openTimeout: CIRCUIT_OPEN_TIMEOUT,
+		trials:      CIRCUIT_HALF_OPEN_REQUESTS,
+		family:      CircuitFamily,
+		now:         time.Now,
+		circuits:    map[string]*circuit{},
+	}
+	for _, option := range options {
+		option(b)
+	}
+	if b.threshold < 1 {
+		b.threshold = 1
+	}
+	if b.trials < 1 {
+		b.trials = 1
+	}
+	return b
+}
+
+var circuitVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// CircuitFamily Returns the endpoint family of a path: its first segment, such as "message" for
+// /message/private/publish.json, or its first two segments for versioned paths, such as "v3/ultragroups".
+func CircuitFamily(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	family := strings.SplitN(segments[0], ".", 2)[0]
+	if circuitVersion.MatchString(family) && len(segments) > 1 {
+		family += "/" + strings.SplitN(segments[1], ".", 2)[0]
+	}
+	return family
+}
+
+// States Returns the state of every circuit that has seen a request, by circuit name.
+func (b *CircuitBreaker) States() map[string]CircuitState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	states := make(map[string]CircuitState, len(b.circuits))
+	for name, c := range b.circuits {
+		states[name] = b.state(c)
+	}
+	return states
+}
+
+// state reports an open circuit whose timeout passed as half-open. The caller holds the lock.
+func (b *CircuitBreaker) state(c *circuit) CircuitState {
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.openTimeout)) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Reset Closes every circuit.
+func (b *CircuitBreaker) Reset() {
+	b.lock.Lock()
+	var transitions []circuitTransition
+	for name, c := range b.circuits {
+		if c.state != CircuitClosed {
+			transitions = append(transitions, circuitTransition{name, c.state, CircuitClosed})
+		}
+	}
+	b.circuits = map[string]*circuit{}
+	b.lock.Unlock()
+	b.notify(transitions)
+}
+
+func (b *CircuitBreaker) notify(transitions []circuitTransition) {
+	if b.onStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		b.onStateChange(t.name, t.from, t.to)
+	}
+}
+
+// set changes the state of a circuit. The caller holds the lock.
+func (b *CircuitBreaker) set(name string, c *circuit, to CircuitState, transitions []circuitTransition) []circuitTransition {
+	if c.state == to {
+		return transitions
+	}
+	transitions = append(transitions, circuitTransition{name, c.state, to})
+	c.state, c.failures, c.inFlight, c.successes = to, 0, 0, 0
+	if to == CircuitOpen {
+		c.openedAt = b.now()
+	}
+	return transitions
+}
+
+// allow admits a request, returning whether it is a trial request of a half-open circuit.
+func (b *CircuitBreaker) allow(name string) (bool, error) {
+	b.lock.Lock()
+	var transitions []circuitTransition
+	defer func() {
+		b.lock.Unlock()
+		b.notify(transitions)
+	}()
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{}
+		b.circuits[name] = c
+	}
+	if c.state == CircuitOpen {
+		if b.state(c) == CircuitOpen {
+			return false, &CircuitOpenError{Name: name, Until: c.openedAt.Add(b.openTimeout)}
+		}
+		transitions = b.set(name, c, CircuitHalfOpen, transitions)
+	}
+	if c.state == CircuitHalfOpen {
+		if c.inFlight+c.successes >= b.trials {
+			return false, &CircuitOpenError{Name: name, Until: b.now()}
+		}
+		c.inFlight++
+		return true, nil
+	}
+	return false, nil
+}
+
+// record stores the outcome of an admitted request. ignore is set for requests cancelled by the caller.
+func (b *CircuitBreaker) record(name string, trial, success, ignore bool) {
+	b.lock.Lock()
+	var transitions []circuitTransition
+	defer func() {
+		b.lock.Unlock()
+		b.notify(transitions)
+	}()
+	c := b.circuits[name]
+	if c == nil {
+		// Removed by Reset
+		return
+	}
+	switch c.state {
+	case CircuitHalfOpen:
+		if !trial {
+			// Admitted before the circuit opened
+			return
+		}
+		c.inFlight--
+		switch {
+		case ignore:
+		case !success:
+			transitions = b.set(name, c, CircuitOpen, transitions)
+		default:
+			c.successes++
+			if c.successes >= b.trials {
+				transitions = b.set(name, c, CircuitClosed, transitions)
+			}
+		}
+	case CircuitClosed:
+		switch {
+		case ignore:
+		case success:
+			c.failures = 0
+		default:
+			c.failures++
+			if c.failures >= b.threshold {
+				transitions = b.set(name, c, CircuitOpen, transitions)
+			}
+		}
+	}
+}
+
+// Transport Returns a transport that sends requests through next while their circuit allows it.
+// A nil next uses http.DefaultTransport.
+func (b *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &circuitTransport{breaker: b, next: next}
+}
+
+type circuitTransport struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+func (t *circuitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	name := req.URL.Host + " " + t.breaker.family(req.URL.Path)
+	trial, err := t.breaker.allow(name)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	success := err == nil && resp.StatusCode < http.StatusInternalServerError
+	t.breaker.record(name, trial, success, !success && req.Context().Err() != nil)
+	return resp, err
+}
+
+// isCircuitOpen reports whether a request failed because its circuit is open
+func isCircuitOpen(err error) bool {
+	var openErr *CircuitOpenError
+	return errors.As(err, &openErr)
+}
+
+// SetCircuitBreaker Sets the circuit breaker of the requests, nil removes it.
+func (rc *RongCloud) SetCircuitBreaker(breaker *CircuitBreaker) {
+	rc.circuitBreaker = breaker
+}
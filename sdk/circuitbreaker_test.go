@@ -0,0 +1,127 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitFamily(t *testing.T) {
+	for path, want := range map[string]string{
+		"/message/private/publish.json": "message",
+		"/user/getToken.json":           "user",
+		"/push.json":                    "push",
+		"/v3/ultragroups/msg/get.json":  "v3/ultragroups",
+		"/v2/message/recall.json":       "v2/message",
+		"/":                             "",
+	} {
+		if got := CircuitFamily(path); got != want {
+			t.Errorf("CircuitFamily(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCircuitBreaker_Transport(t *testing.T) {
+	var fail, calls int32 = 1, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&fail) != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	var changes []string
+	breaker := NewCircuitBreaker(WithCircuitFailureThreshold(2), WithCircuitOpenTimeout(time.Second),
+		WithCircuitHalfOpenRequests(2), WithCircuitStateChange(func(name string, from, to CircuitState) {
+			changes = append(changes, from.String()+">"+to.String())
+		}))
+	breaker.now = func() time.Time { return now }
+	client := &http.Client{Transport: breaker.Transport(nil)}
+	get := func() error {
+		resp, err := client.Get(server.URL + "/user/getToken.json")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	name := strings.TrimPrefix(server.URL, "http://") + " user"
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := get()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Name != name || !openErr.Until.Equal(now.Add(time.Second)) {
+		t.Fatalf("err = %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 || breaker.States()[name] != CircuitOpen {
+		t.Errorf("calls = %d, states = %v", calls, breaker.States())
+	}
+
+	// A failed trial opens the circuit again.
+	now = now.Add(time.Second)
+	if breaker.States()[name] != CircuitHalfOpen {
+		t.Errorf("states = %v", breaker.States())
+	}
+	if err := get(); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(); !errors.As(err, &openErr) {
+		t.Errorf("err after failed trial = %v", err)
+	}
+
+	// 4xx responses are not failures: two successful trials close the circuit.
+	atomic.StoreInt32(&fail, 0)
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if breaker.States()[name] != CircuitClosed {
+		t.Errorf("states = %v", breaker.States())
+	}
+	want := "closed>open,open>half-open,half-open>open,open>half-open,half-open>closed"
+	if strings.Join(changes, ",") != want {
+		t.Errorf("changes = %v", changes)
+	}
+}
+
+func TestRongCloud_CircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if strings.HasPrefix(r.URL.Path, "/user/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code": 1000, "errorMessage": "Internal error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "messageUID": "uid-1"}`))
+	}))
+	defer server.Close()
+
+	client := newRongCloud("key", "secret", NewRegion(server.URL, ""),
+		WithCircuitBreaker(NewCircuitBreaker(WithCircuitFailureThreshold(1))))
+	if _, err := client.UserRegister("u01", "name", "http://portrait"); err == nil {
+		t.Fatal("register did not fail")
+	}
+	_, err := client.UserRegister("u01", "name", "http://portrait")
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+	// Other endpoint families keep their own circuit.
+	if _, err := client.PrivateSend("u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hi"}, "", "", 0, 0, 1, 0, 0); err != nil {
+		t.Error(err)
+	}
+}
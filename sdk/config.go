@@ -0,0 +1,371 @@
+// Client configuration loaded from YAML or JSON files and environment variables
+
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// CONFIG_DEFAULT_PROFILE Name of the profile used when the file has no profiles section or names no default
+	CONFIG_DEFAULT_PROFILE = "default"
+	// CONFIG_DEFAULT_REGION Region used when a profile sets neither region nor primaryDomain
+	CONFIG_DEFAULT_REGION = "bj"
+)
+
+// ConfigFormatJSON and ConfigFormatYAML Formats accepted by ParseConfig
+const (
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+)
+
+// RetryConfig Settings of the RetryTransport, disabled when MaxAttempts is 0 or 1
+type RetryConfig struct {
+	MaxAttempts int `json:"maxAttempts" yaml:"maxAttempts"` // Total attempts including the first one
+	Backoff     int `json:"backoff" yaml:"backoff"`         // Wait before the second attempt in milliseconds, doubled after each attempt
+}
+
+// RateLimitConfig Settings of the RateLimitTransport, disabled when PerSecond is 0
+type RateLimitConfig struct {
+	PerSecond float64 `json:"perSecond" yaml:"perSecond"` // Requests per second
+	Burst     int     `json:"burst" yaml:"burst"`         // Requests that may be sent at once, defaults to 1
+}
+
+// ClientConfig Settings of one app
+type ClientConfig struct {
+	AppKey              string          `json:"appKey" yaml:"appKey"`
+	AppSecret           string          `json:"appSecret" yaml:"appSecret"`
+	Region              string          `json:"region" yaml:"region"`               // bj, sg, sg_b, na or sau
+	PrimaryDomain       string          `json:"primaryDomain" yaml:"primaryDomain"` // Custom API domain, replaces region
+	BackupDomain        string          `json:"backupDomain" yaml:"backupDomain"`   // Custom backup domain, defaults to PrimaryDomain
+	Timeout             int             `json:"timeout" yaml:"timeout"`             // Request timeout in seconds
+	KeepAlive           int             `json:"keepAlive" yaml:"keepAlive"`         // Connection keep-alive in seconds
+	MaxIdleConnsPerHost int             `json:"maxIdleConnsPerHost" yaml:"maxIdleConnsPerHost"`
+	Retry               RetryConfig     `json:"retry" yaml:"retry"`
+	RateLimit           RateLimitConfig `json:"rateLimit" yaml:"rateLimit"`
+}
+
+// Config Named app profiles. A file either holds the fields of a single ClientConfig at the top level,
+// which becomes the "default" profile, or a profiles section:
+//
+//	default: prod
+//	profiles:
+//	  prod:
+//	    appKey: ...
+//	    appSecret: ...
+//	    region: sg
+//	  test:
+//	    appKey: ...
+//	    appSecret: ...
+//	    primaryDomain: https://api.example.com
+type Config struct {
+	Default  string                  `json:"default" yaml:"default"`
+	Profiles map[string]ClientConfig `json:"profiles" yaml:"profiles"`
+}
+
+// configFile accepts both layouts of a config file
+type configFile struct {
+	Config       `yaml:",inline"`
+	ClientConfig `yaml:",inline"`
+}
+
+// ParseConfig parses a config in YAML or JSON
+/*
+*@param  data: Config content.
+*@param  format: ConfigFormatJSON or ConfigFormatYAML.
+*
+*@return Config, error
+ */
+func ParseConfig(data []byte, format string) (Config, error) {
+	var file configFile
+	switch format {
+	case ConfigFormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return Config{}, err
+		}
+	case ConfigFormatYAML:
+		if err := yaml.UnmarshalStrict(data, &file); err != nil {
+			return Config{}, err
+		}
+	default:
+		return Config{}, RCErrorNew(1002, "Paramer 'format' was wrong")
+	}
+
+	conf := file.Config
+	if file.ClientConfig != (ClientConfig{}) {
+		if len(conf.Profiles) > 0 {
+			return Config{}, RCErrorNew(1002, "Paramer 'profiles' cannot be used together with top level settings")
+		}
+		conf.Profiles = map[string]ClientConfig{CONFIG_DEFAULT_PROFILE: file.ClientConfig}
+	}
+	if conf.Default != "" {
+		if _, ok := conf.Profiles[conf.Default]; !ok {
+			return Config{}, RCErrorNew(1002, fmt.Sprintf("Paramer 'default' names unknown profile '%s'", conf.Default))
+		}
+	}
+	return conf, nil
+}
+
+// LoadConfig reads a config file and applies the environment variables on top of it.
+// Files ending in .json are parsed as JSON, all others as YAML. With an empty path only the environment is used.
+// The variables RONGCLOUD_APP_KEY, RONGCLOUD_APP_SECRET, RONGCLOUD_REGION, RONGCLOUD_PRIMARY_DOMAIN,
+// RONGCLOUD_BACKUP_DOMAIN, RONGCLOUD_TIMEOUT, RONGCLOUD_KEEPALIVE, RONGCLOUD_MAX_IDLE_CONNS_PER_HOST,
+// RONGCLOUD_RETRY_MAX_ATTEMPTS, RONGCLOUD_RETRY_BACKOFF, RONGCLOUD_RATE_LIMIT and RONGCLOUD_RATE_BURST
+// override the profile named by RONGCLOUD_PROFILE, or the default profile.
+/*
+*@param  path: Config file, may be empty.
+*
+*@return Config, error
+ */
+func LoadConfig(path string) (Config, error) {
+	var conf Config
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		format := ConfigFormatYAML
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = ConfigFormatJSON
+		}
+		if conf, err = ParseConfig(data, format); err != nil {
+			return Config{}, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if err := conf.applyEnv(os.Getenv); err != nil {
+		return Config{}, err
+	}
+	return conf, nil
+}
+
+// applyEnv overrides the selected profile with the RONGCLOUD_* variables, creating it when missing.
+func (c *Config) applyEnv(getenv func(string) string) error {
+	name := getenv("RONGCLOUD_PROFILE")
+	if name == "" {
+		name = c.defaultName()
+	}
+	profile := c.Profiles[name]
+
+	strs := map[string]*string{
+		"RONGCLOUD_APP_KEY":        &profile.AppKey,
+		"RONGCLOUD_APP_SECRET":     &profile.AppSecret,
+		"RONGCLOUD_REGION":         &profile.Region,
+		"RONGCLOUD_PRIMARY_DOMAIN": &profile.PrimaryDomain,
+		"RONGCLOUD_BACKUP_DOMAIN":  &profile.BackupDomain,
+	}
+	ints := map[string]*int{
+		"RONGCLOUD_TIMEOUT":                 &profile.Timeout,
+		"RONGCLOUD_KEEPALIVE":               &profile.KeepAlive,
+		"RONGCLOUD_MAX_IDLE_CONNS_PER_HOST": &profile.MaxIdleConnsPerHost,
+		"RONGCLOUD_RETRY_MAX_ATTEMPTS":      &profile.Retry.MaxAttempts,
+		"RONGCLOUD_RETRY_BACKOFF":           &profile.Retry.Backoff,
+		"RONGCLOUD_RATE_BURST":              &profile.RateLimit.Burst,
+	}
+	changed := false
+	for key, field := range strs {
+		if v := getenv(key); v != "" {
+			*field, changed = v, true
+		}
+	}
+	for key, field := range ints {
+		if v := getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return RCErrorNew(1002, fmt.Sprintf("Paramer '%s' was wrong", key))
+			}
+			*field, changed = n, true
+		}
+	}
+	if v := getenv("RONGCLOUD_RATE_LIMIT"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return RCErrorNew(1002, "Paramer 'RONGCLOUD_RATE_LIMIT' was wrong")
+		}
+		profile.RateLimit.PerSecond, changed = n, true
+	}
+	if !changed {
+		return nil
+	}
+	// The region and the domains replace each other, so setting one in the environment drops the other from the file.
+	if getenv("RONGCLOUD_REGION") != "" && getenv("RONGCLOUD_PRIMARY_DOMAIN") == "" {
+		profile.PrimaryDomain, profile.BackupDomain = "", ""
+	}
+	if getenv("RONGCLOUD_PRIMARY_DOMAIN") != "" && getenv("RONGCLOUD_REGION") == "" {
+		profile.Region = ""
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]ClientConfig{}
+	}
+	c.Profiles[name] = profile
+	return nil
+}
+
+func (c Config) defaultName() string {
+	if c.Default != "" {
+		return c.Default
+	}
+	return CONFIG_DEFAULT_PROFILE
+}
+
+// Names Returns the profile names in alphabetical order.
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile Returns a validated profile.
+/*
+*@param  name: Profile name, empty for the default profile.
+*
+*@return ClientConfig, error
+ */
+func (c Config) Profile(name string) (ClientConfig, error) {
+	if name == "" {
+		name = c.defaultName()
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return ClientConfig{}, RCErrorNew(1002, fmt.Sprintf("Paramer 'profile' names unknown profile '%s'", name))
+	}
+	if err := profile.Validate(); err != nil {
+		return ClientConfig{}, fmt.Errorf("profile %s: %v", name, err)
+	}
+	return profile, nil
+}
+
+// Client Creates a client from a profile. Unlike NewRongCloud every call returns a new client,
+// so several apps can be used in one process. Keep the client and reuse it.
+/*
+*@param  name: Profile name, empty for the default profile.
+*
+*@return *RongCloud, error
+ */
+func (c Config) Client(name string) (*RongCloud, error) {
+	profile, err := c.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	return profile.NewClient()
+}
+
+// Validate Checks that the settings are complete and consistent.
+func (p ClientConfig) Validate() error {
+	if p.AppKey == "" {
+		return RCErrorNew(1002, "Paramer 'appKey' is required")
+	}
+	if p.AppSecret == "" {
+		return RCErrorNew(1002, "Paramer 'appSecret' is required")
+	}
+	if p.PrimaryDomain != "" {
+		if p.Region != "" {
+			return RCErrorNew(1002, "Paramer 'region' and 'primaryDomain' cannot be used together")
+		}
+		if !validDomain(p.PrimaryDomain) {
+			return RCErrorNew(1002, "Paramer 'primaryDomain' was wrong")
+		}
+	} else if p.BackupDomain != "" {
+		return RCErrorNew(1002, "Paramer 'primaryDomain' is required")
+	}
+	if p.BackupDomain != "" && !validDomain(p.BackupDomain) {
+		return RCErrorNew(1002, "Paramer 'backupDomain' was wrong")
+	}
+	if p.Region != "" {
+		if _, ok := RegionByName(p.Region); !ok {
+			return RCErrorNew(1002, "Paramer 'region' was wrong")
+		}
+	}
+	if p.Timeout < 0 {
+		return RCErrorNew(1002, "Paramer 'timeout' was wrong")
+	}
+	if p.KeepAlive < 0 {
+		return RCErrorNew(1002, "Paramer 'keepAlive' was wrong")
+	}
+	if p.MaxIdleConnsPerHost < 0 {
+		return RCErrorNew(1002, "Paramer 'maxIdleConnsPerHost' was wrong")
+	}
+	if p.Retry.MaxAttempts < 0 {
+		return RCErrorNew(1002, "Paramer 'retry.maxAttempts' was wrong")
+	}
+	if p.Retry.Backoff < 0 {
+		return RCErrorNew(1002, "Paramer 'retry.backoff' was wrong")
+	}
+	if p.RateLimit.PerSecond < 0 {
+		return RCErrorNew(1002, "Paramer 'rateLimit.perSecond' was wrong")
+	}
+	if p.RateLimit.Burst < 0 || (p.RateLimit.Burst > 0 && p.RateLimit.PerSecond == 0) {
+		return RCErrorNew(1002, "Paramer 'rateLimit.burst' was wrong")
+	}
+	return nil
+}
+
+func validDomain(domain string) bool {
+	u, err := url.Parse(domain)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/")
+}
+
+// options returns the options that apply the timeout, keep-alive and idle connection settings.
+func (p ClientConfig) options() []rongCloudOption {
+	var options []rongCloudOption
+	if p.Timeout > 0 {
+		options = append(options, WithTimeout(time.Duration(p.Timeout)))
+	}
+	if p.KeepAlive > 0 {
+		options = append(options, WithKeepAlive(time.Duration(p.KeepAlive)))
+	}
+	if p.MaxIdleConnsPerHost > 0 {
+		options = append(options, WithMaxIdleConnsPerHost(p.MaxIdleConnsPerHost))
+	}
+	return options
+}
+
+// NewClient Validates the settings and creates a client. Each call returns a new client, the retry
+// and rate limit transports wrap its own transport, so the rate limit is not shared with other clients.
+/*
+*@param  options: Applied after the settings of the profile, for example WithTransport.
+*
+*@return *RongCloud, error
+ */
+func (p ClientConfig) NewClient(options ...rongCloudOption) (*RongCloud, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	var region Region
+	if p.PrimaryDomain != "" {
+		region = NewRegion(strings.TrimSuffix(p.PrimaryDomain, "/"), strings.TrimSuffix(p.BackupDomain, "/"))
+	} else {
+		name := p.Region
+		if name == "" {
+			name = CONFIG_DEFAULT_REGION
+		}
+		region, _ = RegionByName(name)
+	}
+
+	client := newRongCloud(p.AppKey, p.AppSecret, region, append(p.options(), options...)...)
+	transport := client.GetHttpTransport()
+	if p.RateLimit.PerSecond > 0 {
+		transport = NewRateLimitTransport(transport, p.RateLimit.PerSecond, p.RateLimit.Burst)
+	}
+	if p.Retry.MaxAttempts > 1 {
+		transport = NewRetryTransport(transport, p.Retry.MaxAttempts, time.Duration(p.Retry.Backoff)*time.Millisecond)
+	}
+	client.SetHttpTransport(transport)
+	return client, nil
+}
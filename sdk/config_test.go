@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const testConfigYAML = `
+default: prod
+profiles:
+  prod:
+    appKey: prod-key
+    appSecret: prod-secret
+    region: sg
+    timeout: 20
+    retry:
+      maxAttempts: 3
+      backoff: 100
+    rateLimit:
+      perSecond: 50
+      burst: 10
+  test:
+    appKey: test-key
+    appSecret: test-secret
+    primaryDomain: https://api.example.com/
+`
+
+func TestParseConfig_Profiles(t *testing.T) {
+	conf, err := ParseConfig([]byte(testConfigYAML), ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := conf.Names(); len(names) != 2 || names[0] != "prod" || names[1] != "test" {
+		t.Errorf("names = %v", names)
+	}
+	prod, err := conf.Profile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.AppKey != "prod-key" || prod.Region != "sg" || prod.Timeout != 20 || prod.Retry.MaxAttempts != 3 || prod.RateLimit.Burst != 10 {
+		t.Errorf("prod = %+v", prod)
+	}
+	if _, err := conf.Profile("missing"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+
+	client, err := conf.Client("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.rongCloudURI != "https://api.example.com" || client.backupDomain != "https://api.example.com" {
+		t.Errorf("domains = %s, %s", client.rongCloudURI, client.backupDomain)
+	}
+	if client == GetRongCloud() {
+		t.Error("profile client must not be the shared client")
+	}
+}
+
+func TestParseConfig_TopLevelJSON(t *testing.T) {
+	conf, err := ParseConfig([]byte(`{"appKey": "k", "appSecret": "s", "region": "na"}`), ConfigFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := conf.Client("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.rongCloudURI != REGION_NA.primaryDomain {
+		t.Errorf("uri = %s", client.rongCloudURI)
+	}
+
+	for _, data := range []string{
+		`{"appKey": "k", "profiles": {"a": {"appKey": "k"}}}`,
+		`{"default": "b", "profiles": {"a": {"appKey": "k"}}}`,
+		`{"appKey": "k", "unknown": 1}`,
+	} {
+		if _, err := ParseConfig([]byte(data), ConfigFormatJSON); err == nil {
+			t.Errorf("%s accepted", data)
+		}
+	}
+}
+
+func TestClientConfig_Validate(t *testing.T) {
+	valid := ClientConfig{AppKey: "k", AppSecret: "s"}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(*ClientConfig){
+		"no key":             func(c *ClientConfig) { c.AppKey = "" },
+		"no secret":          func(c *ClientConfig) { c.AppSecret = "" },
+		"bad region":         func(c *ClientConfig) { c.Region = "mars" },
+		"region and domain":  func(c *ClientConfig) { c.Region, c.PrimaryDomain = "bj", "https://a.example.com" },
+		"domain scheme":      func(c *ClientConfig) { c.PrimaryDomain = "a.example.com" },
+		"backup only":        func(c *ClientConfig) { c.BackupDomain = "https://b.example.com" },
+		"negative timeout":   func(c *ClientConfig) { c.Timeout = -1 },
+		"negative attempts":  func(c *ClientConfig) { c.Retry.MaxAttempts = -1 },
+		"burst without rate": func(c *ClientConfig) { c.RateLimit.Burst = 5 },
+	} {
+		c := valid
+		change(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	conf, err := ParseConfig([]byte(testConfigYAML), ConfigFormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"RONGCLOUD_PROFILE":        "test",
+		"RONGCLOUD_APP_SECRET":     "env-secret",
+		"RONGCLOUD_REGION":         "bj",
+		"RONGCLOUD_TIMEOUT":        "5",
+		"RONGCLOUD_RATE_LIMIT":     "2.5",
+		"RONGCLOUD_RETRY_BACKOFF":  "50",
+		"RONGCLOUD_UNRELATED_NAME": "x",
+	}
+	if err := conf.applyEnv(func(key string) string { return env[key] }); err != nil {
+		t.Fatal(err)
+	}
+	test, err := conf.Profile("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.AppKey != "test-key" || test.AppSecret != "env-secret" || test.Region != "bj" || test.PrimaryDomain != "" ||
+		test.Timeout != 5 || test.RateLimit.PerSecond != 2.5 || test.Retry.Backoff != 50 {
+		t.Errorf("test = %+v", test)
+	}
+	if prod := conf.Profiles["prod"]; prod.AppSecret != "prod-secret" {
+		t.Errorf("prod changed: %+v", prod)
+	}
+
+	env = map[string]string{"RONGCLOUD_TIMEOUT": "soon"}
+	if err := conf.applyEnv(func(key string) string { return env[key] }); err == nil {
+		t.Error("bad number accepted")
+	}
+}
+
+func TestLoadConfig_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, _ := json.Marshal(map[string]interface{}{"appKey": "k", "appSecret": "s"})
+	path := filepath.Join(dir, "rongcloud.json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conf.Profile(CONFIG_DEFAULT_PROFILE); err != nil {
+		t.Error(err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("missing file accepted")
+	}
+}
+
+func TestClientConfig_NewClientTransports(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "userId": "u01", "token": "t"}`))
+	}))
+	defer server.Close()
+
+	conf := ClientConfig{
+		AppKey:        "k",
+		AppSecret:     "s",
+		PrimaryDomain: server.URL,
+		Retry:         RetryConfig{MaxAttempts: 2, Backoff: 1},
+		RateLimit:     RateLimitConfig{PerSecond: 100, Burst: 5},
+	}
+	client, err := conf.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.GetHttpTransport().(*RetryTransport); !ok {
+		t.Fatalf("transport = %T", client.GetHttpTransport())
+	}
+	if _, err := client.UserRegister("u01", "Alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d", calls)
+	}
+}
+
+func TestRegionByName(t *testing.T) {
+	if r, ok := RegionByName("SG_B"); !ok || r != REGION_SG_B {
+		t.Errorf("SG_B = %v, %v", r, ok)
+	}
+	if _, ok := RegionByName("mars"); ok {
+		t.Error("unknown region accepted")
+	}
+	if r := NewRegion("https://a.example.com", ""); r.backupDomain != "https://a.example.com" {
+		t.Errorf("backup = %s", r.backupDomain)
+	}
+}
@@ -0,0 +1,231 @@
+// App Secret providers, so that the secret can be rotated without restarting the process
+
+package sdk
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials The secrets used to sign requests
+type Credentials struct {
+	AppSecret         string // Signs every request
+	PreviousAppSecret string // Set during a rotation window, signs the request again when the server answers 401
+}
+
+// CredentialProvider Supplies the App Secret. It is consulted for every request, so it must be fast and safe for
+// concurrent use. When it fails or returns an empty secret, the secret passed to NewRongCloud is used.
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+type staticCredentials Credentials
+
+func (c staticCredentials) Credentials() (Credentials, error) {
+	return Credentials(c), nil
+}
+
+// NewStaticCredentialProvider creates a CredentialProvider that always returns the same secret
+func NewStaticCredentialProvider(appSecret string) CredentialProvider {
+	return staticCredentials{AppSecret: appSecret}
+}
+
+// RotatingCredentials A CredentialProvider whose secret is replaced with Rotate. After a rotation the old
+// secret stays available as PreviousAppSecret for the rotation window, while the server may still expect it.
+type RotatingCredentials struct {
+	window    time.Duration
+	now       func() time.Time
+	lock      sync.RWMutex
+	current   string
+	previous  string
+	rotatedAt time.Time
+}
+
+// NewRotatingCredentials creates a RotatingCredentials
+/*
+*@param  appSecret: Current App Secret.
+*@param  window: How long the old secret is tried after a rotation.
+*
+*@return *RotatingCredentials
+ */
+func NewRotatingCredentials(appSecret string, window time.Duration) *RotatingCredentials {
+	return &RotatingCredentials{current: appSecret, window: window, now: time.Now}
+}
+
+// Rotate Signs the following requests with appSecret, keeping the old secret for the rotation window.
+func (c *RotatingCredentials) Rotate(appSecret string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if appSecret == c.current {
+		return
+	}
+	c.previous, c.current, c.rotatedAt = c.current, appSecret, c.now()
+}
+
+// Credentials implements CredentialProvider
+func (c *RotatingCredentials) Credentials() (Credentials, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	creds := Credentials{AppSecret: c.current}
+	if c.previous != "" && c.now().Sub(c.rotatedAt) < c.window {
+		creds.PreviousAppSecret = c.previous
+	}
+	return creds, nil
+}
+
+// FileCredentialProvider A CredentialProvider that reads the App Secret from a file, such as a mounted
+// Kubernetes secret, and rotates to the new content when the file changes. Surrounding whitespace is ignored.
+// When the file cannot be read or is empty the last secret is kept, and Err reports the problem.
+type FileCredentialProvider struct {
+	path      string
+	interval  time.Duration
+	secrets   *RotatingCredentials
+	lock      sync.Mutex
+	checkedAt time.Time
+	modTime   time.Time
+	size      int64
+	err       error
+}
+
+// NewFileCredentialProvider creates a FileCredentialProvider. The file must hold a secret at creation.
+/*
+*@param  path: File holding the App Secret.
+*@param  interval: Minimum time between two checks of the file, 0 checks on every request.
+*@param  window: How long the old secret is tried after the file changed.
+*
+*@return *FileCredentialProvider, error
+ */
+func NewFileCredentialProvider(path string, interval, window time.Duration) (*FileCredentialProvider, error) {
+	p := &FileCredentialProvider{path: path, interval: interval}
+	secret, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+	p.secrets = NewRotatingCredentials(secret, window)
+	p.checkedAt = p.secrets.now()
+	return p, nil
+}
+
+// Credentials implements CredentialProvider
+func (p *FileCredentialProvider) Credentials() (Credentials, error) {
+	p.lock.Lock()
+	now := p.secrets.now()
+	if now.Sub(p.checkedAt) >= p.interval {
+		p.checkedAt = now
+		p.refresh()
+	}
+	p.lock.Unlock()
+	return p.secrets.Credentials()
+}
+
+// Err Returns the error of the last check of the file, nil when it succeeded.
+func (p *FileCredentialProvider) Err() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
+
+func (p *FileCredentialProvider) refresh() {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		p.err = err
+		return
+	}
+	if info.ModTime().Equal(p.modTime) && info.Size() == p.size && p.err == nil {
+		return
+	}
+	secret, err := p.read()
+	if err != nil {
+		return
+	}
+	p.secrets.Rotate(secret)
+}
+
+// read loads the secret and records the file state. The caller holds the lock, or owns p.
+func (p *FileCredentialProvider) read() (string, error) {
+	info, err := os.Stat(p.path)
+	if err == nil {
+		var data []byte
+		if data, err = ioutil.ReadFile(p.path); err == nil {
+			if secret := strings.TrimSpace(string(data)); secret != "" {
+				p.modTime, p.size, p.err = info.ModTime(), info.Size(), nil
+				return secret, nil
+			}
+			err = RCErrorNew(1002, "Paramer 'appSecret' is required in "+p.path)
+		}
+	}
+	p.err = err
+	return "", err
+}
+
+// credentials returns the secrets to sign with, falling back to the secret passed to NewRongCloud.
+func (rc *RongCloud) credentials() Credentials {
+	if rc.credentialProvider != nil {
+		if creds, err := rc.credentialProvider.Credentials(); err == nil && creds.AppSecret != "" {
+			return creds
+		}
+	}
+	return Credentials{AppSecret: rc.appSecret}
+}
+
+// SetCredentialProvider Replaces the source of the App Secret, nil goes back to the secret passed to NewRongCloud.
+func (rc *RongCloud) SetCredentialProvider(provider CredentialProvider) {
+	rc.credentialProvider = provider
+}
+
+// transport returns the shared transport, wrapped to retry with the previous secret when a provider is set,
+// and behind the circuit breaker when one is set.
+func (rc *RongCloud) transport() http.RoundTripper {
+	transport := rc.globalTransport
+	if rc.credentialProvider != nil {
+		transport = &rotationTransport{rc: rc, next: transport}
+	}
+	if rc.circuitBreaker != nil {
+		transport = rc.circuitBreaker.Transport(transport)
+	}
+	return transport
+}
+
+// rotationTransport signs a request again with PreviousAppSecret when the server rejects the current secret.
+type rotationTransport struct {
+	rc   *RongCloud
+	next http.RoundTripper
+}
+
+func (t *rotationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	previous := t.rc.credentials().PreviousAppSecret
+	if previous == "" || req.Header.Get("Signature") == "" {
+		return next.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	retry.Header.Set("Signature", Sign(previous, req.Header.Get("Nonce"), req.Header.Get("Timestamp")))
+	if body != nil {
+		retry.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return next.RoundTrip(retry)
+}
@@ -0,0 +1,111 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingCredentials(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	creds := NewRotatingCredentials("old", time.Minute)
+	creds.now = func() time.Time { return now }
+
+	if c, _ := creds.Credentials(); c.AppSecret != "old" || c.PreviousAppSecret != "" {
+		t.Errorf("before rotation = %+v", c)
+	}
+	creds.Rotate("new")
+	if c, _ := creds.Credentials(); c.AppSecret != "new" || c.PreviousAppSecret != "old" {
+		t.Errorf("during window = %+v", c)
+	}
+	now = now.Add(time.Minute)
+	if c, _ := creds.Credentials(); c.AppSecret != "new" || c.PreviousAppSecret != "" {
+		t.Errorf("after window = %+v", c)
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secret")
+	if _, err := NewFileCredentialProvider(path, 0, time.Minute); err == nil {
+		t.Error("missing file accepted")
+	}
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewFileCredentialProvider(path, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := provider.Credentials(); c.AppSecret != "old" {
+		t.Errorf("initial = %+v", c)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("newer"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := provider.Credentials(); c.AppSecret != "newer" || c.PreviousAppSecret != "old" {
+		t.Errorf("after change = %+v", c)
+	}
+
+	// An empty file keeps the last secret.
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := provider.Credentials(); c.AppSecret != "newer" {
+		t.Errorf("after emptying = %+v", c)
+	}
+	if provider.Err() == nil {
+		t.Error("empty file not reported")
+	}
+}
+
+func TestRongCloud_CredentialRotation(t *testing.T) {
+	// The server still expects the old secret, as if the rotation had not reached it yet.
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get("Signature"))
+		if r.Header.Get("Signature") != Sign("old", r.Header.Get("Nonce"), r.Header.Get("Timestamp")) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code": 1004, "errorMessage": "Incorrect signature"}`))
+			return
+		}
+		_ = r.ParseForm()
+		_, _ = w.Write([]byte(`{"code": 200, "userId": "` + r.Form.Get("userId") + `", "token": "t"}`))
+	}))
+	defer server.Close()
+
+	creds := NewRotatingCredentials("old", time.Minute)
+	client := newRongCloud("key", "unused", NewRegion(server.URL, ""), WithCredentialProvider(creds))
+	creds.Rotate("new")
+	res, err := client.UserRegister("u01", "Alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.UserID != "u01" || len(signatures) != 2 {
+		t.Errorf("result = %+v, signatures = %v", res, signatures)
+	}
+
+	// Once the window is over the old secret is no longer tried.
+	creds.now = func() time.Time { return time.Now().Add(time.Hour) }
+	signatures = nil
+	if _, err := client.UserRegister("u01", "Alice", ""); err == nil || len(signatures) != 1 {
+		t.Errorf("err = %v, signatures = %v", err, signatures)
+	}
+
+	client.SetCredentialProvider(NewStaticCredentialProvider("old"))
+	if _, err := client.UserRegister("u01", "Alice", ""); err != nil {
+		t.Error(err)
+	}
+	if !client.checkCallbackSignature("n", "1", Sign("old", "n", "1")) || client.checkCallbackSignature("n", "1", Sign("new", "n", "1")) {
+		t.Error("callback signature not checked against the provider")
+	}
+}
@@ -0,0 +1,502 @@
+// Typed message expansion built on SetMessageExpansion, DeleteMessageExpansion, QueryMessageExpansion
+// and the ultra group expansion API
+
+package sdk
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EXPANSION_MAX_KEYS Maximum number of expansion keys a message can hold
+	EXPANSION_MAX_KEYS = 300
+	// EXPANSION_MAX_KEYS_PER_CALL Maximum number of keys set or deleted per request
+	EXPANSION_MAX_KEYS_PER_CALL = 100
+	// EXPANSION_KEY_MAX_LENGTH Maximum length of an expansion key
+	EXPANSION_KEY_MAX_LENGTH = 32
+	// EXPANSION_VALUE_MAX_LENGTH Maximum length of an expansion value
+	EXPANSION_VALUE_MAX_LENGTH = 4096
+	// EXPANSION_QUERY_PAGE_SIZE Number of keys QueryMessageExpansion returns per page
+	EXPANSION_QUERY_PAGE_SIZE = 100
+
+	// EXPANSION_REACTION_PREFIX Prefix of the keys that hold reaction sets
+	EXPANSION_REACTION_PREFIX = "rct_"
+)
+
+// ExpansionTarget The message whose expansion is managed
+type ExpansionTarget struct {
+	MsgUID           string           // Message UID (required)
+	ConversationType ConversationType // ConversationTypePrivate, ConversationTypeGroup or ConversationTypeUG
+	TargetId         string           // User ID, group ID or ultra group ID
+	BusChannel       string           // Ultra group channel ID
+	IsSyncSender     int              // 1 delivers the expansion operation message to the operator's other clients, private and group only
+}
+
+// ExpansionGetAll Queries every expansion key of a message, walking all pages of QueryMessageExpansion.
+/*
+*@param  target: The message.
+*
+*@return map[string]MessageExpansionItem: Items keyed by expansion key.
+*@return error
+ */
+func (rc *RongCloud) ExpansionGetAll(target ExpansionTarget) (map[string]MessageExpansionItem, error) {
+	if err := validateExpansionTarget(target); err != nil {
+		return nil, err
+	}
+	all := map[string]MessageExpansionItem{}
+	if target.ConversationType == ConversationTypeUG {
+		items, _, err := rc.UltraGroup().ExpansionQuery(target.TargetId, target.MsgUID, target.BusChannel)
+		for _, item := range items {
+			all[item.Key] = MessageExpansionItem(item)
+		}
+		return all, err
+	}
+	for page := 1; ; page++ {
+		items, err := rc.QueryMessageExpansion(target.MsgUID, page)
+		if err != nil {
+			return all, err
+		}
+		added := 0
+		for _, item := range items {
+			if _, ok := all[item.Key]; !ok {
+				added++
+			}
+			all[item.Key] = item
+		}
+		// A page without new keys means the server ignored pageNo and returned everything at once.
+		if len(items) < EXPANSION_QUERY_PAGE_SIZE || added == 0 || len(all) >= EXPANSION_MAX_KEYS {
+			return all, nil
+		}
+	}
+}
+
+// ExpansionSet Sets expansion key/values of a message, splitting them into requests of at most 100 keys.
+// Keys and values are checked against the documented limits before anything is sent. When more than one request
+// is needed, the keys the message already holds are read first and counted against EXPANSION_MAX_KEYS,
+// so that a call the server would reject halfway is rejected before any key is set.
+/*
+*@param  target: The message.
+*@param  userId: Operator user ID.
+*@param  extra: Key/values to set.
+*
+*@return error
+ */
+func (rc *RongCloud) ExpansionSet(target ExpansionTarget, userId string, extra map[string]string) error {
+	if err := validateExpansionTarget(target); err != nil {
+		return err
+	}
+	if userId == "" {
+		return RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	if err := validateExpansion(extra); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) > EXPANSION_MAX_KEYS_PER_CALL {
+		existing, err := rc.ExpansionGetAll(target)
+		if err != nil {
+			return err
+		}
+		total := len(existing)
+		for _, key := range keys {
+			if _, ok := existing[key]; !ok {
+				total++
+			}
+		}
+		if total > EXPANSION_MAX_KEYS {
+			return RCErrorNew(1002, "Paramer 'extra' and the existing keys can not exceed 300 keys")
+		}
+	}
+	for start := 0; start < len(keys); start += EXPANSION_MAX_KEYS_PER_CALL {
+		end := start + EXPANSION_MAX_KEYS_PER_CALL
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunk := make(map[string]string, end-start)
+		for _, key := range keys[start:end] {
+			chunk[key] = extra[key]
+		}
+		if err := rc.expansionSet(target, userId, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpansionDelete Deletes expansion keys of a message, splitting them into requests of at most 100 keys.
+/*
+*@param  target: The message.
+*@param  userId: Operator user ID.
+*@param  keys: Keys to delete.
+*
+*@return error
+ */
+func (rc *RongCloud) ExpansionDelete(target ExpansionTarget, userId string, keys ...string) error {
+	if err := validateExpansionTarget(target); err != nil {
+		return err
+	}
+	if userId == "" {
+		return RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	if len(keys) == 0 {
+		return RCErrorNew(1002, "Paramer 'keys' is required")
+	}
+	for start := 0; start < len(keys); start += EXPANSION_MAX_KEYS_PER_CALL {
+		end := start + EXPANSION_MAX_KEYS_PER_CALL
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := rc.expansionDelete(target, userId, keys[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpansionSetStruct Sets the fields of a struct as expansion key/values, see MarshalExpansion.
+/*
+*@param  target: The message.
+*@param  userId: Operator user ID.
+*@param  v: Struct or pointer to struct.
+*
+*@return error
+ */
+func (rc *RongCloud) ExpansionSetStruct(target ExpansionTarget, userId string, v interface{}) error {
+	extra, err := MarshalExpansion(v)
+	if err != nil {
+		return err
+	}
+	return rc.ExpansionSet(target, userId, extra)
+}
+
+// ExpansionGetStruct Reads the expansion of a message into a struct, see UnmarshalExpansion.
+/*
+*@param  target: The message.
+*@param  v: Pointer to struct.
+*
+*@return error
+ */
+func (rc *RongCloud) ExpansionGetStruct(target ExpansionTarget, v interface{}) error {
+	items, err := rc.ExpansionGetAll(target)
+	if err != nil {
+		return err
+	}
+	extra := make(map[string]string, len(items))
+	for key, item := range items {
+		extra[key] = item.Value
+	}
+	return UnmarshalExpansion(extra, v)
+}
+
+// ExpansionIncrement Adds delta to the integer stored under key and returns the new value. A missing key counts as 0.
+// The current value is read with ExpansionGetAll and the result is written back with ExpansionSet,
+// so concurrent writers of the same message may overwrite each other.
+/*
+*@param  target: The message.
+*@param  userId: Operator user ID.
+*@param  key: Counter key.
+*@param  delta: Amount to add, may be negative.
+*
+*@return int64, error
+ */
+func (rc *RongCloud) ExpansionIncrement(target ExpansionTarget, userId, key string, delta int64) (int64, error) {
+	if err := validateExpansionKey(key); err != nil {
+		return 0, err
+	}
+	items, err := rc.ExpansionGetAll(target)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if item, ok := items[key]; ok && item.Value != "" {
+		if n, err = strconv.ParseInt(item.Value, 10, 64); err != nil {
+			return 0, RCErrorNew(1002, "Expansion '"+key+"' is not a counter")
+		}
+	}
+	n += delta
+	if err := rc.ExpansionSet(target, userId, map[string]string{key: strconv.FormatInt(n, 10)}); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ExpansionReactionAdd Adds the operator to the users of a reaction. Each reaction is stored under
+// EXPANSION_REACTION_PREFIX + reaction as a JSON array of user IDs. Like ExpansionIncrement this is a read-modify-write.
+/*
+*@param  target: The message.
+*@param  userId: The reacting user, also the operator.
+*@param  reaction: Reaction name, e.g. "thumbsup". Must be usable in an expansion key.
+*
+*@return []string: The users of the reaction after the change, sorted.
+*@return error
+ */
+func (rc *RongCloud) ExpansionReactionAdd(target ExpansionTarget, userId, reaction string) ([]string, error) {
+	return rc.expansionReactionModify(target, userId, reaction, true)
+}
+
+// ExpansionReactionRemove Removes the operator from the users of a reaction, deleting the key once nobody is left.
+/*
+*@param  target: The message.
+*@param  userId: The reacting user, also the operator.
+*@param  reaction: Reaction name.
+*
+*@return []string: The users of the reaction after the change, sorted.
+*@return error
+ */
+func (rc *RongCloud) ExpansionReactionRemove(target ExpansionTarget, userId, reaction string) ([]string, error) {
+	return rc.expansionReactionModify(target, userId, reaction, false)
+}
+
+// ExpansionReactions Queries every reaction of a message.
+/*
+*@param  target: The message.
+*
+*@return map[string][]string: Sorted user IDs keyed by reaction name.
+*@return error
+ */
+func (rc *RongCloud) ExpansionReactions(target ExpansionTarget) (map[string][]string, error) {
+	items, err := rc.ExpansionGetAll(target)
+	if err != nil {
+		return nil, err
+	}
+	reactions := map[string][]string{}
+	for key, item := range items {
+		if !strings.HasPrefix(key, EXPANSION_REACTION_PREFIX) {
+			continue
+		}
+		users, err := decodeReactionUsers(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		reactions[strings.TrimPrefix(key, EXPANSION_REACTION_PREFIX)] = users
+	}
+	return reactions, nil
+}
+
+func (rc *RongCloud) expansionReactionModify(target ExpansionTarget, userId, reaction string, add bool) ([]string, error) {
+	if userId == "" {
+		return nil, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	key := EXPANSION_REACTION_PREFIX + reaction
+	if reaction == "" {
+		return nil, RCErrorNew(1002, "Paramer 'reaction' is required")
+	}
+	if err := validateExpansionKey(key); err != nil {
+		return nil, err
+	}
+	items, err := rc.ExpansionGetAll(target)
+	if err != nil {
+		return nil, err
+	}
+	current, err := decodeReactionUsers(items[key].Value)
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	if add {
+		users = mergeTags(current, []string{userId}, nil)
+	} else {
+		users = mergeTags(current, nil, []string{userId})
+	}
+	if equalTags(current, users) {
+		return users, nil
+	}
+	if len(users) == 0 {
+		return users, rc.ExpansionDelete(target, userId, key)
+	}
+	value, err := json.Marshal(users)
+	if err != nil {
+		return nil, err
+	}
+	return users, rc.ExpansionSet(target, userId, map[string]string{key: string(value)})
+}
+
+func (rc *RongCloud) expansionSet(target ExpansionTarget, userId string, extra map[string]string) error {
+	if target.ConversationType == ConversationTypeUG {
+		_, err := rc.UltraGroup().ExpansionSet(target.TargetId, userId, target.MsgUID, target.BusChannel, extra)
+		return err
+	}
+	return rc.SetMessageExpansion(target.MsgUID, userId, strconv.Itoa(int(target.ConversationType)), target.TargetId,
+		extra, target.IsSyncSender)
+}
+
+func (rc *RongCloud) expansionDelete(target ExpansionTarget, userId string, keys []string) error {
+	if target.ConversationType == ConversationTypeUG {
+		_, err := rc.UltraGroup().ExpansionDelete(target.TargetId, userId, target.MsgUID, target.BusChannel, keys...)
+		return err
+	}
+	return rc.DeleteMessageExpansion(target.MsgUID, userId, strconv.Itoa(int(target.ConversationType)), target.TargetId,
+		target.IsSyncSender, keys...)
+}
+
+// MarshalExpansion Converts the exported fields of a struct to expansion key/values.
+// Keys come from the json tag, or the field name when there is none. "-" skips a field and omitempty skips zero values.
+// String fields are stored as they are, all other fields as their JSON encoding.
+/*
+*@param  v: Struct or pointer to struct.
+*
+*@return map[string]string, error
+ */
+func MarshalExpansion(v interface{}) (map[string]string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, RCErrorNew(1002, "Paramer 'v' must be a struct")
+	}
+	extra := map[string]string{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		key, omitEmpty, ok := expansionField(rt.Field(i))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitEmpty && isZeroValue(fv) {
+			continue
+		}
+		if fv.Kind() == reflect.String {
+			extra[key] = fv.String()
+			continue
+		}
+		value, err := json.Marshal(fv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		extra[key] = string(value)
+	}
+	return extra, validateExpansion(extra)
+}
+
+// UnmarshalExpansion Fills a struct from expansion key/values, the reverse of MarshalExpansion. Missing keys leave fields untouched.
+/*
+*@param  extra: Expansion key/values.
+*@param  v: Pointer to struct.
+*
+*@return error
+ */
+func UnmarshalExpansion(extra map[string]string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return RCErrorNew(1002, "Paramer 'v' must be a pointer to struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		key, _, ok := expansionField(rt.Field(i))
+		if !ok {
+			continue
+		}
+		value, ok := extra[key]
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.String {
+			fv.SetString(value)
+			continue
+		}
+		if err := json.Unmarshal([]byte(value), fv.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expansionField returns the key of a struct field and whether it has omitempty, or false if the field is skipped.
+func expansionField(field reflect.StructField) (string, bool, bool) {
+	if field.PkgPath != "" {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	key := parts[0]
+	if key == "" {
+		key = field.Name
+	}
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return key, omitEmpty, true
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+func decodeReactionUsers(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var users []string
+	if err := json.Unmarshal([]byte(value), &users); err != nil {
+		return nil, err
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+func validateExpansionTarget(target ExpansionTarget) error {
+	if target.MsgUID == "" {
+		return RCErrorNew(1002, "Paramer 'msgUID' is required")
+	}
+	if target.TargetId == "" {
+		return RCErrorNew(1002, "Paramer 'targetId' is required")
+	}
+	switch target.ConversationType {
+	case ConversationTypePrivate, ConversationTypeGroup, ConversationTypeUG:
+		return nil
+	}
+	return RCErrorNew(1002, "Paramer 'conversationType' must be 1, 3 or 10")
+}
+
+func validateExpansion(extra map[string]string) error {
+	if len(extra) == 0 {
+		return RCErrorNew(1002, "Paramer 'extra' is required")
+	}
+	if len(extra) > EXPANSION_MAX_KEYS {
+		return RCErrorNew(1002, "Paramer 'extra' can not exceed 300 keys")
+	}
+	for key, value := range extra {
+		if err := validateExpansionKey(key); err != nil {
+			return err
+		}
+		if len([]rune(value)) > EXPANSION_VALUE_MAX_LENGTH {
+			return RCErrorNew(1002, "Expansion '"+key+"' can not exceed 4096 characters")
+		}
+	}
+	return nil
+}
+
+// validateExpansionKey accepts letters, digits and + = - _, up to 32 characters.
+func validateExpansionKey(key string) error {
+	if key == "" {
+		return RCErrorNew(1002, "Expansion key can not be empty")
+	}
+	if len(key) > EXPANSION_KEY_MAX_LENGTH {
+		return RCErrorNew(1002, "Expansion key '"+key+"' can not exceed 32 characters")
+	}
+	for _, r := range key {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("+=-_", r) {
+			continue
+		}
+		return RCErrorNew(1002, "Expansion key '"+key+"' contains invalid characters")
+	}
+	return nil
+}
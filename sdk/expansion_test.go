@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type expansionState struct {
+	Status   string         `json:"status"`
+	Score    int            `json:"score,omitempty"`
+	Pinned   bool           `json:"pinned"`
+	Labels   []string       `json:"labels,omitempty"`
+	Votes    map[string]int `json:"votes,omitempty"`
+	Internal string         `json:"-"`
+}
+
+func TestMarshalExpansion(t *testing.T) {
+	in := expansionState{Status: "done", Pinned: true, Labels: []string{"a", "b"}, Internal: "x"}
+	extra, err := MarshalExpansion(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"status": "done", "pinned": "true", "labels": `["a","b"]`}
+	if !reflect.DeepEqual(extra, want) {
+		t.Errorf("unexpected key/values: %v", extra)
+	}
+
+	var out expansionState
+	extra["score"] = "7"
+	if err := UnmarshalExpansion(extra, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Score, in.Internal = 7, ""
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("unexpected struct: %+v", out)
+	}
+
+	if err := UnmarshalExpansion(extra, out); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
+
+func TestValidateExpansion(t *testing.T) {
+	if err := validateExpansion(map[string]string{"a+b=c-d_e": "v"}); err != nil {
+		t.Error(err)
+	}
+	if err := validateExpansion(map[string]string{"a.b": "v"}); err == nil {
+		t.Error("expected error for invalid character")
+	}
+	if err := validateExpansion(map[string]string{strings.Repeat("k", 33): "v"}); err == nil {
+		t.Error("expected error for long key")
+	}
+	if err := validateExpansion(map[string]string{"k": strings.Repeat("v", 4097)}); err == nil {
+		t.Error("expected error for long value")
+	}
+	if err := validateExpansionTarget(ExpansionTarget{MsgUID: "m", TargetId: "t", ConversationType: ConversationTypeSystem}); err == nil {
+		t.Error("expected error for system conversation")
+	}
+}
+
+func TestRongCloud_ExpansionSetLimit(t *testing.T) {
+	existing := 250
+	var sets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/message/expansion/set.json" {
+			sets++
+			_, _ = w.Write([]byte(`{"code": 200}`))
+			return
+		}
+		// The query ignores pageNo and returns every key at once.
+		extra := map[string]map[string]interface{}{}
+		for i := 0; i < existing; i++ {
+			extra["old"+strconv.Itoa(i)] = map[string]interface{}{"v": "x", "ts": 1}
+		}
+		body, _ := json.Marshal(map[string]interface{}{"code": 200, "extraContent": extra})
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	target := ExpansionTarget{MsgUID: "msg01", ConversationType: ConversationTypeGroup, TargetId: "g01"}
+	extra := map[string]string{}
+	for i := 0; i < 120; i++ {
+		extra["new"+strconv.Itoa(i)] = "v"
+	}
+	err := rc.ExpansionSet(target, "u01", extra)
+	if e, ok := err.(CodeResult); !ok || e.Code != 1002 || sets != 0 {
+		t.Fatalf("err = %v, sets = %d", err, sets)
+	}
+
+	// Keys the message already holds are replaced, not added.
+	for i := 0; i < 80; i++ {
+		extra["old"+strconv.Itoa(i)] = "v"
+		delete(extra, "new"+strconv.Itoa(i))
+	}
+	if err := rc.ExpansionSet(target, "u01", extra); err != nil || sets != 2 {
+		t.Errorf("err = %v, sets = %d", err, sets)
+	}
+}
+
+func TestRongCloud_ExpansionReactionAdd(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	target := ExpansionTarget{MsgUID: "BRGM-DEN2-01E4-BRGM", ConversationType: ConversationTypeGroup, TargetId: "g01"}
+	users, err := rc.ExpansionReactionAdd(target, "u01", "thumbsup")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(users)
+}
@@ -57,6 +57,26 @@ type CheckFriendResult struct {
 	Result int    `json:"result"` // Unidirectional check result: 1: Not in my friend list; 2: In my friend list; Bidirectional check result: 1: In both users' friend lists; 2: Not in both users' friend lists; 3: Only in the current user's friend list; 4: Only in the target user's friend list
 }
 
+// Relationship Decodes Result for the direction the check was made with.
+// A unidirectional check only looks at the user's own list, so a friend there is reported as FriendRelationshipOnlyMine.
+func (r CheckFriendResult) Relationship(direction FriendCheckDirection) FriendRelationship {
+	if direction == FriendCheckUnidirectional {
+		if r.Result == 2 {
+			return FriendRelationshipOnlyMine
+		}
+		return FriendRelationshipNone
+	}
+	switch r.Result {
+	case 1:
+		return FriendRelationshipMutual
+	case 3:
+		return FriendRelationshipOnlyMine
+	case 4:
+		return FriendRelationshipOnlyTheirs
+	}
+	return FriendRelationshipNone
+}
+
 type GetPermissionResult struct {
 	PermissionSettings []PermissionSetting `json:"permissionSettings"` // User permission list
 }
@@ -66,6 +86,41 @@ type PermissionSetting struct {
 	Type   int    `json:"type"`   // Permission type
 }
 
+// Permission Returns Type as a FriendPermissionType
+func (p PermissionSetting) Permission() FriendPermissionType {
+	return FriendPermissionType(p.Type)
+}
+
+// FriendCheckDirection Which friend lists FriendCheck looks at
+type FriendCheckDirection int
+
+const (
+	FriendCheckUnidirectional FriendCheckDirection = 1 // FriendCheckUnidirectional only the user's friend list
+	FriendCheckBidirectional  FriendCheckDirection = 2 // FriendCheckBidirectional the friend lists of both users
+)
+
+// FriendRelationship Relationship between a user and a target
+type FriendRelationship int
+
+const (
+	FriendRelationshipNone       FriendRelationship = iota // FriendRelationshipNone in neither friend list
+	FriendRelationshipMutual                               // FriendRelationshipMutual in both friend lists
+	FriendRelationshipOnlyMine                             // FriendRelationshipOnlyMine only in the user's friend list
+	FriendRelationshipOnlyTheirs                           // FriendRelationshipOnlyTheirs only in the target's friend list
+)
+
+// FriendPermissionType Who may add a user as a friend
+type FriendPermissionType int
+
+const (
+	FriendPermissionFree    FriendPermissionType = 1 // FriendPermissionFree anyone can add the user directly
+	FriendPermissionVerify  FriendPermissionType = 2 // FriendPermissionVerify adding the user needs the user's approval
+	FriendPermissionDenyAll FriendPermissionType = 3 // FriendPermissionDenyAll nobody can add the user
+)
+
+// FRIEND_PERMISSION_MAX_USERS Maximum number of userIds per FriendSetPermission or FriendGetPermission request
+const FRIEND_PERMISSION_MAX_USERS = 100
+
 // Helper function: validate user ID for friend operations
 func (rc *RongCloud) validateFriendUserId(userId string) error {
 	if userId == "" {
@@ -257,22 +312,30 @@ func (rc *RongCloud) PagingGetFriends(getFriendsModel PagingGetFriendsModel) (Qu
 
 // Check friend relationships
 func (rc *RongCloud) FriendCheckFriends(userId string, targetIds ...string) (CheckFriendsResult, error) {
-	result := CheckFriendsResult{}
-
 	// Validate required parameters
 	if err := rc.validateFriendUserId(userId); err != nil {
-		return result, err
+		return CheckFriendsResult{}, err
 	}
 	if err := rc.validateTargetIds(targetIds); err != nil {
-		return result, err
+		return CheckFriendsResult{}, err
 	}
 
+	return rc.friendCheck(userId, 0, removeDuplicates(targetIds))
+}
+
+// friendCheck sends /friend/check.json. A checkType of 0 is omitted and leaves the server default.
+func (rc *RongCloud) friendCheck(userId string, checkType FriendCheckDirection, targetIds []string) (CheckFriendsResult, error) {
+	result := CheckFriendsResult{}
+
 	req := httplib.Post(rc.rongCloudURI + "/friend/check.json")
 	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
 	rc.fillHeader(req)
 
 	req.Param("userId", userId)
-	req.Param("targetIds", strings.Join(removeDuplicates(targetIds), ","))
+	req.Param("targetIds", strings.Join(targetIds, ","))
+	if checkType != 0 {
+		req.Param("checkType", strconv.Itoa(int(checkType)))
+	}
 
 	resp, err := rc.do(req)
 	if err != nil {
@@ -346,3 +409,99 @@ func (rc *RongCloud) FriendGetPermission(userIds ...string) (GetPermissionResult
 
 	return result, nil
 }
+
+// FriendCheck Checks the relationship between a user and any number of targets,
+// splitting the targets into requests of at most FRIEND_MAX_TARGETS.
+/*
+*@param  userId: User ID.
+*@param  direction: FriendCheckUnidirectional or FriendCheckBidirectional.
+*@param  targetIds: Target user IDs.
+*
+*@return map[string]FriendRelationship: Relationship keyed by target user ID.
+*@return error
+ */
+func (rc *RongCloud) FriendCheck(userId string, direction FriendCheckDirection, targetIds ...string) (map[string]FriendRelationship, error) {
+	if err := rc.validateFriendUserId(userId); err != nil {
+		return nil, err
+	}
+	if err := rc.validateTargetIds(targetIds); err != nil {
+		return nil, err
+	}
+	if direction != FriendCheckUnidirectional && direction != FriendCheckBidirectional {
+		return nil, RCErrorNew(1002, "Parameter 'direction' was wrong")
+	}
+
+	relationships := map[string]FriendRelationship{}
+	err := friendChunk(removeDuplicates(targetIds), FRIEND_MAX_TARGETS, func(chunk []string) error {
+		result, err := rc.friendCheck(userId, direction, chunk)
+		if err != nil {
+			return err
+		}
+		for _, r := range result.Results {
+			relationships[r.UserId] = r.Relationship(direction)
+		}
+		return nil
+	})
+	return relationships, err
+}
+
+// FriendPermissionSet Sets who may add any number of users as a friend,
+// splitting the users into FriendSetPermission requests of at most FRIEND_PERMISSION_MAX_USERS.
+/*
+*@param  permission: FriendPermissionFree, FriendPermissionVerify or FriendPermissionDenyAll.
+*@param  userIds: User IDs.
+*
+*@return error
+ */
+func (rc *RongCloud) FriendPermissionSet(permission FriendPermissionType, userIds ...string) error {
+	if permission < FriendPermissionFree || permission > FriendPermissionDenyAll {
+		return RCErrorNew(1002, "Parameter 'permissionType' was wrong")
+	}
+	if len(userIds) == 0 {
+		return RCErrorNew(1002, "Parameter 'userIds' is required")
+	}
+	return friendChunk(removeDuplicates(userIds), FRIEND_PERMISSION_MAX_USERS, func(chunk []string) error {
+		_, err := rc.FriendSetPermission(int(permission), chunk...)
+		return err
+	})
+}
+
+// FriendPermissionGet Queries who may add any number of users as a friend,
+// splitting the users into FriendGetPermission requests of at most FRIEND_PERMISSION_MAX_USERS.
+/*
+*@param  userIds: User IDs.
+*
+*@return map[string]FriendPermissionType: Permission keyed by user ID.
+*@return error
+ */
+func (rc *RongCloud) FriendPermissionGet(userIds ...string) (map[string]FriendPermissionType, error) {
+	if len(userIds) == 0 {
+		return nil, RCErrorNew(1002, "Parameter 'userIds' is required")
+	}
+	permissions := map[string]FriendPermissionType{}
+	err := friendChunk(removeDuplicates(userIds), FRIEND_PERMISSION_MAX_USERS, func(chunk []string) error {
+		result, err := rc.FriendGetPermission(chunk...)
+		if err != nil {
+			return err
+		}
+		for _, setting := range result.PermissionSettings {
+			permissions[setting.UserId] = setting.Permission()
+		}
+		return nil
+	})
+	return permissions, err
+}
+
+// friendChunk calls fn with consecutive slices of ids holding at most max items.
+func friendChunk(ids []string, max int, fn func([]string) error) error {
+	for start := 0; start < len(ids); start += max {
+		end := start + max
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := fn(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -139,3 +139,73 @@ func TestRongCloud_FriendGetPermission(t *testing.T) {
 	t.Log("Get friend permission result:", formatFriendJSON(result))
 	t.Log("Error:", err)
 }
+
+func TestCheckFriendResult_Relationship(t *testing.T) {
+	cases := []struct {
+		result    int
+		direction FriendCheckDirection
+		want      FriendRelationship
+	}{
+		{1, FriendCheckUnidirectional, FriendRelationshipNone},
+		{2, FriendCheckUnidirectional, FriendRelationshipOnlyMine},
+		{1, FriendCheckBidirectional, FriendRelationshipMutual},
+		{2, FriendCheckBidirectional, FriendRelationshipNone},
+		{3, FriendCheckBidirectional, FriendRelationshipOnlyMine},
+		{4, FriendCheckBidirectional, FriendRelationshipOnlyTheirs},
+	}
+	for _, c := range cases {
+		if got := (CheckFriendResult{Result: c.result}).Relationship(c.direction); got != c.want {
+			t.Errorf("result %d direction %d: got %d, want %d", c.result, c.direction, got, c.want)
+		}
+	}
+}
+
+func TestFriendChunk(t *testing.T) {
+	var sizes []int
+	ids := make([]string, 45)
+	err := friendChunk(ids, FRIEND_MAX_TARGETS, func(chunk []string) error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[0] != 20 || sizes[2] != 5 {
+		t.Errorf("unexpected chunks: %v %v", sizes, err)
+	}
+
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	if _, err := rc.FriendCheck("user001", 0, "user002"); err == nil {
+		t.Error("expected error for unknown direction")
+	}
+	if err := rc.FriendPermissionSet(0, "user001"); err == nil {
+		t.Error("expected error for unknown permission")
+	}
+}
+
+// Test checking friend relationships with an explicit direction
+func TestRongCloud_FriendCheck(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+
+	result, err := rc.FriendCheck("user001", FriendCheckBidirectional, "user002", "user003")
+	t.Log("Check friends result:", formatFriendJSON(result))
+	t.Log("Error:", err)
+}
+
+// Test setting and reading the friend permission of users
+func TestRongCloud_FriendPermissionSet(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+
+	if err := rc.FriendPermissionSet(FriendPermissionVerify, "user001", "user002"); err != nil {
+		t.Log("Error:", err)
+		return
+	}
+	result, err := rc.FriendPermissionGet("user001", "user002")
+	t.Log("Get permission result:", formatFriendJSON(result))
+	t.Log("Error:", err)
+}
@@ -0,0 +1,254 @@
+// Friend graph sync that brings friend lists in line with a source of truth
+
+package sdk
+
+import (
+	"sort"
+)
+
+const (
+	// FRIEND_PAGE_SIZE Page size used when walking PagingGetFriends
+	FRIEND_PAGE_SIZE = 100
+	// FRIEND_MAX_TARGETS Maximum number of targetIds per FriendDelete or FriendCheckFriends request
+	FRIEND_MAX_TARGETS = 20
+
+	// FriendOptTypeVerify FriendAdd follows the target user's friend verification setting
+	FriendOptTypeVerify = 1
+	// FriendOptTypeDirect FriendAdd adds the friend without the target user's approval
+	FriendOptTypeDirect = 2
+)
+
+// FriendEntry Desired profile of one friend in a user's friend list
+type FriendEntry struct {
+	RemarkName       string `json:"remarkName"`       // Remark name, empty leaves the current remark untouched
+	FriendExtProfile string `json:"friendExtProfile"` // Friend extended profile, empty leaves the current profile untouched
+}
+
+// FriendGraph Friend lists keyed by user ID, then by friend user ID
+type FriendGraph map[string]map[string]FriendEntry
+
+// FriendGraphDiff Operations that turn the current friend lists into the desired ones
+type FriendGraphDiff struct {
+	Adds    []FriendProfileModel `json:"adds"`    // Friends to add, with the profile to set after adding
+	Deletes map[string][]string  `json:"deletes"` // User ID -> friends to delete
+	Updates []FriendProfileModel `json:"updates"` // Existing friends whose remark or extended profile differs
+}
+
+// FriendRelations Mutual and one-way relationships of a user as reported by FriendCheckFriends
+type FriendRelations struct {
+	Mutual     []string `json:"mutual"`     // In both friend lists
+	OnlyMine   []string `json:"onlyMine"`   // Only in the user's friend list
+	OnlyTheirs []string `json:"onlyTheirs"` // Only in the target's friend list
+	NotFriends []string `json:"notFriends"` // In neither friend list
+}
+
+// Targets Returns the target IDs with the given relationship.
+func (r FriendRelations) Targets(relationship FriendRelationship) []string {
+	switch relationship {
+	case FriendRelationshipMutual:
+		return r.Mutual
+	case FriendRelationshipOnlyMine:
+		return r.OnlyMine
+	case FriendRelationshipOnlyTheirs:
+		return r.OnlyTheirs
+	}
+	return r.NotFriends
+}
+
+// add appends a target to the list of its relationship.
+func (r *FriendRelations) add(targetId string, relationship FriendRelationship) {
+	switch relationship {
+	case FriendRelationshipMutual:
+		r.Mutual = append(r.Mutual, targetId)
+	case FriendRelationshipOnlyMine:
+		r.OnlyMine = append(r.OnlyMine, targetId)
+	case FriendRelationshipOnlyTheirs:
+		r.OnlyTheirs = append(r.OnlyTheirs, targetId)
+	default:
+		r.NotFriends = append(r.NotFriends, targetId)
+	}
+}
+
+// FriendGraphGet Reads the complete friend lists of the users, walking every page of PagingGetFriends.
+/*
+*@param  userIds: User IDs.
+*
+*@return FriendGraph: Friend lists with the current remark and extended profile of every friend.
+*@return error
+ */
+func (rc *RongCloud) FriendGraphGet(userIds ...string) (FriendGraph, error) {
+	graph := FriendGraph{}
+	for _, userId := range userIds {
+		if err := rc.validateFriendUserId(userId); err != nil {
+			return nil, err
+		}
+		if _, ok := graph[userId]; ok {
+			continue
+		}
+		friends := map[string]FriendEntry{}
+		pageToken := ""
+		for {
+			res, err := rc.PagingGetFriends(PagingGetFriendsModel{UserId: userId, PageToken: pageToken, Size: FRIEND_PAGE_SIZE})
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range res.Friends {
+				friends[f.UserId] = FriendEntry{RemarkName: f.RemarkName, FriendExtProfile: f.FriendExtProfile}
+			}
+			if res.PageToken == "" || res.PageToken == pageToken || len(res.Friends) == 0 {
+				break
+			}
+			pageToken = res.PageToken
+		}
+		graph[userId] = friends
+	}
+	return graph, nil
+}
+
+// Diff Computes the operations that turn current into g. Only the users present in g are compared,
+// and a user of g that is missing from current is treated as having no friends.
+// FriendSetProfile cannot clear a value, so empty fields of g never produce an update.
+/*
+*@param  current: Friend lists as read by FriendGraphGet.
+*
+*@return FriendGraphDiff: Operations sorted by user ID and friend ID.
+ */
+func (g FriendGraph) Diff(current FriendGraph) FriendGraphDiff {
+	diff := FriendGraphDiff{Deletes: map[string][]string{}}
+	for _, userId := range g.users() {
+		want, have := g[userId], current[userId]
+		for _, friendId := range friendIds(want) {
+			entry := want[friendId]
+			profile := FriendProfileModel{
+				UserId:           userId,
+				TargetId:         friendId,
+				RemarkName:       entry.RemarkName,
+				FriendExtProfile: entry.FriendExtProfile,
+			}
+			existing, ok := have[friendId]
+			if !ok {
+				diff.Adds = append(diff.Adds, profile)
+				continue
+			}
+			if entry.RemarkName != "" && entry.RemarkName != existing.RemarkName ||
+				entry.FriendExtProfile != "" && entry.FriendExtProfile != existing.FriendExtProfile {
+				diff.Updates = append(diff.Updates, profile)
+			}
+		}
+		for _, friendId := range friendIds(have) {
+			if _, ok := want[friendId]; !ok {
+				diff.Deletes[userId] = append(diff.Deletes[userId], friendId)
+			}
+		}
+	}
+	return diff
+}
+
+// Empty Reports whether the diff holds no operations.
+func (d FriendGraphDiff) Empty() bool {
+	return len(d.Adds) == 0 && len(d.Deletes) == 0 && len(d.Updates) == 0
+}
+
+// FriendGraphApply Performs the operations of a diff: deletes with FriendDelete in chunks of FRIEND_MAX_TARGETS,
+// adds with FriendAdd using FriendOptTypeDirect followed by FriendSetProfile when a profile is given,
+// then profile updates with FriendSetProfile.
+/*
+*@param  diff: Operations computed by FriendGraph.Diff.
+*
+*@return FriendGraphDiff: The operations performed, also when an error stops the run.
+*@return error
+ */
+func (rc *RongCloud) FriendGraphApply(diff FriendGraphDiff) (FriendGraphDiff, error) {
+	done := FriendGraphDiff{Deletes: map[string][]string{}}
+	for _, userId := range sortedMapKeys(diff.Deletes) {
+		err := friendChunk(diff.Deletes[userId], FRIEND_MAX_TARGETS, func(chunk []string) error {
+			if _, err := rc.FriendDelete(userId, chunk...); err != nil {
+				return err
+			}
+			done.Deletes[userId] = append(done.Deletes[userId], chunk...)
+			return nil
+		})
+		if err != nil {
+			return done, err
+		}
+	}
+
+	optType := FriendOptTypeDirect
+	for _, profile := range diff.Adds {
+		if _, err := rc.FriendAdd(FriendModel{UserId: profile.UserId, TargetId: profile.TargetId, OptType: &optType}); err != nil {
+			return done, err
+		}
+		if profile.RemarkName != "" || profile.FriendExtProfile != "" {
+			if _, err := rc.FriendSetProfile(profile); err != nil {
+				return done, err
+			}
+		}
+		done.Adds = append(done.Adds, profile)
+	}
+
+	for _, profile := range diff.Updates {
+		if _, err := rc.FriendSetProfile(profile); err != nil {
+			return done, err
+		}
+		done.Updates = append(done.Updates, profile)
+	}
+	return done, nil
+}
+
+// FriendGraphSync Reads the current friend lists of the users in desired, computes the diff and applies it.
+// Friends that are not in desired are deleted, so every user in desired must list all of their friends.
+/*
+*@param  desired: Source of truth.
+*
+*@return FriendGraphDiff: The operations performed, also when an error stops the run.
+*@return error
+ */
+func (rc *RongCloud) FriendGraphSync(desired FriendGraph) (FriendGraphDiff, error) {
+	current, err := rc.FriendGraphGet(desired.users()...)
+	if err != nil {
+		return FriendGraphDiff{Deletes: map[string][]string{}}, err
+	}
+	return rc.FriendGraphApply(desired.Diff(current))
+}
+
+// FriendGraphRelations Classifies the relationship between a user and each target with a bidirectional FriendCheck,
+// in chunks of FRIEND_MAX_TARGETS targets.
+/*
+*@param  userId: User ID.
+*@param  targetIds: Target user IDs.
+*
+*@return FriendRelations: Target IDs by relationship, each list sorted.
+*@return error
+ */
+func (rc *RongCloud) FriendGraphRelations(userId string, targetIds ...string) (FriendRelations, error) {
+	relations := FriendRelations{}
+	relationships, err := rc.FriendCheck(userId, FriendCheckBidirectional, targetIds...)
+	if err != nil {
+		return relations, err
+	}
+	for targetId, relationship := range relationships {
+		relations.add(targetId, relationship)
+	}
+	for _, ids := range [][]string{relations.Mutual, relations.OnlyMine, relations.OnlyTheirs, relations.NotFriends} {
+		sort.Strings(ids)
+	}
+	return relations, nil
+}
+
+func (g FriendGraph) users() []string {
+	users := make([]string, 0, len(g))
+	for userId := range g {
+		users = append(users, userId)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func friendIds(friends map[string]FriendEntry) []string {
+	ids := make([]string, 0, len(friends))
+	for friendId := range friends {
+		ids = append(ids, friendId)
+	}
+	sort.Strings(ids)
+	return ids
+}
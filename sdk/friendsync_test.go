@@ -0,0 +1,92 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFriendGraph_Diff(t *testing.T) {
+	desired := FriendGraph{
+		"u01": {
+			"u02": {RemarkName: "Bob"},
+			"u03": {},
+			"u04": {FriendExtProfile: `{"level":2}`},
+		},
+		"u05": {"u01": {RemarkName: "Alice"}},
+	}
+	current := FriendGraph{
+		"u01": {
+			"u02": {RemarkName: "Bobby"},
+			"u04": {RemarkName: "kept", FriendExtProfile: `{"level":2}`},
+			"u06": {},
+			"u07": {},
+		},
+		"u08": {"u01": {}},
+	}
+
+	diff := desired.Diff(current)
+	wantAdds := []FriendProfileModel{
+		{UserId: "u01", TargetId: "u03"},
+		{UserId: "u05", TargetId: "u01", RemarkName: "Alice"},
+	}
+	if !reflect.DeepEqual(diff.Adds, wantAdds) {
+		t.Errorf("unexpected adds: %+v", diff.Adds)
+	}
+	if !reflect.DeepEqual(diff.Deletes, map[string][]string{"u01": {"u06", "u07"}}) {
+		t.Errorf("unexpected deletes: %+v", diff.Deletes)
+	}
+	if !reflect.DeepEqual(diff.Updates, []FriendProfileModel{{UserId: "u01", TargetId: "u02", RemarkName: "Bob"}}) {
+		t.Errorf("unexpected updates: %+v", diff.Updates)
+	}
+	if !desired.Diff(FriendGraph{
+		"u01": {"u02": {RemarkName: "Bob"}, "u03": {RemarkName: "x"}, "u04": {FriendExtProfile: `{"level":2}`}},
+		"u05": {"u01": {RemarkName: "Alice"}},
+	}).Empty() {
+		t.Error("expected an empty diff for matching graphs")
+	}
+}
+
+func TestRongCloud_FriendGraphRelations(t *testing.T) {
+	var checkType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checkType = r.FormValue("checkType")
+		_, _ = w.Write([]byte(`{"code": 200, "results": [{"userId": "u4", "result": 4}, {"userId": "u1", "result": 1},
+			{"userId": "u3", "result": 3}, {"userId": "u2", "result": 2}, {"userId": "u0", "result": 1}]}`))
+	}))
+	defer server.Close()
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+
+	relations, err := rc.FriendGraphRelations("user001", "u0", "u1", "u2", "u3", "u4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FriendRelations{Mutual: []string{"u0", "u1"}, OnlyMine: []string{"u3"}, OnlyTheirs: []string{"u4"}, NotFriends: []string{"u2"}}
+	if !reflect.DeepEqual(relations, want) || checkType != "2" {
+		t.Errorf("relations = %+v, checkType = %s", relations, checkType)
+	}
+	if got := relations.Targets(FriendRelationshipOnlyTheirs); !reflect.DeepEqual(got, []string{"u4"}) {
+		t.Errorf("Targets = %v", got)
+	}
+}
+
+func TestRongCloud_FriendGraphSync(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	done, err := rc.FriendGraphSync(FriendGraph{
+		"user001": {"user002": {RemarkName: "Bob"}, "user003": {}},
+	})
+	t.Log("Sync result:", formatFriendJSON(done))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	relations, err := rc.FriendGraphRelations("user001", "user002", "user003")
+	t.Log("Relations:", formatFriendJSON(relations))
+	t.Log("Error:", err)
+}
@@ -0,0 +1,288 @@
+// History export that walks a whole time range of a conversation
+
+package sdk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const (
+	// HistorySourcePrivate One-to-one chat, GetPrivateHistoryMessage
+	HistorySourcePrivate = "private"
+	// HistorySourceGroup Group chat, GetGroupHistoryMessage
+	HistorySourceGroup = "group"
+	// HistorySourceChatroom Chatroom, GetChatroomHistoryMessage
+	HistorySourceChatroom = "chatroom"
+	// HistorySourceUltraGroup Ultra group as seen by a user, GetUltraGroupHistoryMessage
+	HistorySourceUltraGroup = "ultragroup"
+	// HistorySourceUltraGroupChannel Ultra group channel without a user perspective, UGHistoryQuery
+	HistorySourceUltraGroupChannel = "ultragroupChannel"
+
+	// HistoryFormatJSONL One JSON encoded HistoryMessage per line
+	HistoryFormatJSONL = "jsonl"
+	// HistoryFormatCSV CSV with a header row, see HistoryCSVHeader
+	HistoryFormatCSV = "csv"
+
+	// HISTORY_EXPORT_PAGE_SIZE Default page size of history export requests
+	HISTORY_EXPORT_PAGE_SIZE = 50
+	// UG_HISTORY_MAX_SPAN Maximum time span of one UGHistoryQuery request, in milliseconds
+	UG_HISTORY_MAX_SPAN = 14 * 24 * 60 * 60 * 1000
+)
+
+// HistoryCSVHeader Column names of HistoryFormatCSV output
+var HistoryCSVHeader = []string{"targetId", "fromUserId", "messageUID", "msgTime", "objectName", "content", "expansion", "extraContent", "busChannel"}
+
+// HistoryExportRequest Conversation and time range to export
+type HistoryExportRequest struct {
+	Source     string // One of the HistorySource* constants
+	UserId     string // User whose view of the conversation is exported, required except for HistorySourceUltraGroupChannel
+	TargetId   string // Target user, group, chatroom or ultra group ID
+	BusChannel string // Ultra group channel ID
+	FromUserId string // Only messages of this sender, HistorySourceUltraGroupChannel only
+	StartTime  int64  // Start of the range in milliseconds, inclusive
+	EndTime    int64  // End of the range in milliseconds, inclusive
+	PageSize   int    // Messages per request, 0 uses HISTORY_EXPORT_PAGE_SIZE
+	Format     string // HistoryFormatJSONL or HistoryFormatCSV
+
+	// Checkpoint resumes an interrupted export. Nil starts at StartTime.
+	Checkpoint *HistoryCheckpoint
+	// OnCheckpoint is called after every page has been written. Returning an error stops the export.
+	OnCheckpoint func(HistoryCheckpoint) error
+}
+
+// HistoryCheckpoint Position of an export, JSON encodable so it can be persisted and passed back to resume
+type HistoryCheckpoint struct {
+	Cursor       int64    `json:"cursor"`       // Timestamp the next request starts at
+	IncludeStart bool     `json:"includeStart"` // Whether the next request includes messages sent at Cursor
+	Seen         []string `json:"seen"`         // UIDs already written that were sent at Cursor
+	Exported     int      `json:"exported"`     // Messages written so far
+	Done         bool     `json:"done"`         // The whole range has been exported
+	// Truncated Timestamps at which a full page of messages was sent in the same millisecond. The history APIs
+	// cannot page past them, so messages of those milliseconds beyond the first page may be missing from the export.
+	Truncated []int64 `json:"truncated,omitempty"`
+}
+
+// historyFetcher queries messages from cursor on. It returns the page and the end of the time window it covered.
+type historyFetcher func(cursor int64, includeStart bool) ([]HistoryMessage, int64, error)
+
+// HistoryExport Writes every message of a conversation in the requested time range to w, in ascending time order.
+// Pages are chained by restarting at the newest timestamp of the previous page with IncludeStart set, and messages
+// sent in the same millisecond are deduplicated by MsgUID. Pages are expected in ascending time order, as documented.
+// When PageSize or more messages share a millisecond only the first page of them can be read: the export moves on
+// and records the millisecond in HistoryCheckpoint.Truncated, check it when the export is done.
+/*
+*@param  req: Conversation, range and output format.
+*@param  w: Output.
+*
+*@return HistoryCheckpoint: Where the export stopped, pass it back in req.Checkpoint to resume after an error.
+*@return error
+ */
+func (rc *RongCloud) HistoryExport(req HistoryExportRequest, w io.Writer) (HistoryCheckpoint, error) {
+	checkpoint := HistoryCheckpoint{Cursor: req.StartTime, IncludeStart: true}
+	if req.Checkpoint != nil {
+		checkpoint = *req.Checkpoint
+	}
+	if req.TargetId == "" {
+		return checkpoint, RCErrorNew(1002, "Paramer 'targetId' is required")
+	}
+	if req.StartTime <= 0 || req.EndTime < req.StartTime {
+		return checkpoint, RCErrorNew(1002, "Paramer 'startTime' and 'endTime' were wrong")
+	}
+	if req.Source != HistorySourceUltraGroupChannel && req.UserId == "" {
+		return checkpoint, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = HISTORY_EXPORT_PAGE_SIZE
+	}
+	fetch, err := rc.historyFetcher(req)
+	if err != nil {
+		return checkpoint, err
+	}
+
+	var write func([]HistoryMessage) error
+	switch req.Format {
+	case HistoryFormatJSONL, "":
+		enc := json.NewEncoder(w)
+		write = func(page []HistoryMessage) error {
+			for _, m := range page {
+				if err := enc.Encode(m); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case HistoryFormatCSV:
+		cw := csv.NewWriter(w)
+		if req.Checkpoint == nil {
+			if err := cw.Write(HistoryCSVHeader); err != nil {
+				return checkpoint, err
+			}
+		}
+		write = func(page []HistoryMessage) error {
+			for _, m := range page {
+				if err := cw.Write(historyCSVRecord(m)); err != nil {
+					return err
+				}
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return checkpoint, RCErrorNew(1002, "Paramer 'format' must be jsonl or csv")
+	}
+
+	return historyWalk(fetch, req.EndTime, req.PageSize, checkpoint, write, req.OnCheckpoint)
+}
+
+// historyWalk drives fetch from the checkpoint until the range is exhausted.
+func historyWalk(fetch historyFetcher, endTime int64, pageSize int, checkpoint HistoryCheckpoint,
+	write func([]HistoryMessage) error, onCheckpoint func(HistoryCheckpoint) error) (HistoryCheckpoint, error) {
+	for !checkpoint.Done {
+		page, windowEnd, err := fetch(checkpoint.Cursor, checkpoint.IncludeStart)
+		if err != nil {
+			return checkpoint, err
+		}
+
+		seen := make(map[string]bool, len(checkpoint.Seen))
+		for _, uid := range checkpoint.Seen {
+			seen[uid] = true
+		}
+		var fresh []HistoryMessage
+		newest := checkpoint.Cursor
+		for _, m := range page {
+			if m.MsgTime < checkpoint.Cursor || m.MsgTime > endTime || seen[m.MsgUID] {
+				continue
+			}
+			seen[m.MsgUID] = true
+			fresh = append(fresh, m)
+			if m.MsgTime > newest {
+				newest = m.MsgTime
+			}
+		}
+		sort.SliceStable(fresh, func(i, j int) bool {
+			return fresh[i].MsgTime < fresh[j].MsgTime
+		})
+		if err := write(fresh); err != nil {
+			return checkpoint, err
+		}
+		checkpoint.Exported += len(fresh)
+
+		switch {
+		case newest > checkpoint.Cursor:
+			// Restart at the newest timestamp, remembering what was already written for it.
+			checkpoint.Cursor, checkpoint.IncludeStart, checkpoint.Seen = newest, true, nil
+			for _, m := range fresh {
+				if m.MsgTime == newest {
+					checkpoint.Seen = append(checkpoint.Seen, m.MsgUID)
+				}
+			}
+		case len(fresh) > 0:
+			// Everything was sent at the cursor, keep asking for the same millisecond.
+			for _, m := range fresh {
+				checkpoint.Seen = append(checkpoint.Seen, m.MsgUID)
+			}
+		case checkpoint.IncludeStart && len(page) > 0:
+			// Only duplicates at the cursor are left, move past it. A page filled with them may hide more
+			// messages of the same millisecond, which no query can reach.
+			atCursor := 0
+			for _, m := range page {
+				if m.MsgTime == checkpoint.Cursor {
+					atCursor++
+				}
+			}
+			if atCursor >= pageSize {
+				checkpoint.Truncated = append(checkpoint.Truncated, checkpoint.Cursor)
+			}
+			checkpoint.IncludeStart, checkpoint.Seen = false, nil
+		case windowEnd < endTime:
+			// Nothing left in this window, continue with the next one.
+			checkpoint.Cursor, checkpoint.IncludeStart, checkpoint.Seen = windowEnd, false, nil
+		default:
+			checkpoint.Done = true
+		}
+
+		if onCheckpoint != nil {
+			if err := onCheckpoint(checkpoint); err != nil {
+				return checkpoint, err
+			}
+		}
+	}
+	return checkpoint, nil
+}
+
+func (rc *RongCloud) historyFetcher(req HistoryExportRequest) (historyFetcher, error) {
+	var query func(QueryHistoryMessageModel) (HistoryMessageResponse, error)
+	switch req.Source {
+	case HistorySourcePrivate:
+		query = rc.GetPrivateHistoryMessage
+	case HistorySourceGroup:
+		query = rc.GetGroupHistoryMessage
+	case HistorySourceChatroom:
+		query = rc.GetChatroomHistoryMessage
+	case HistorySourceUltraGroup:
+		query = rc.GetUltraGroupHistoryMessage
+	case HistorySourceUltraGroupChannel:
+		return func(cursor int64, includeStart bool) ([]HistoryMessage, int64, error) {
+			// UGHistoryQuery excludes startTime and spans at most 14 days.
+			start := cursor
+			if includeStart {
+				start--
+			}
+			end := start + UG_HISTORY_MAX_SPAN
+			if end > req.EndTime {
+				end = req.EndTime
+			}
+			res, err := rc.UGHistoryQuery(req.TargetId, req.BusChannel, start, end, req.FromUserId, req.PageSize)
+			if err != nil {
+				return nil, end, err
+			}
+			page := make([]HistoryMessage, 0, len(res.Data))
+			for _, m := range res.Data {
+				page = append(page, HistoryMessage{
+					TargetID:     m.GroupId,
+					FromUserID:   m.FromUserId,
+					MsgUID:       m.MsgUID,
+					MsgTime:      m.MsgTime,
+					ObjectName:   m.ObjectName,
+					Content:      m.Content,
+					Expansion:    m.Expansion,
+					ExtraContent: m.ExtraContent,
+					BusChannel:   m.BusChannel,
+				})
+			}
+			return page, end, nil
+		}, nil
+	default:
+		return nil, RCErrorNew(1002, "Paramer 'source' was wrong")
+	}
+	return func(cursor int64, includeStart bool) ([]HistoryMessage, int64, error) {
+		res, err := query(QueryHistoryMessageModel{
+			UserID:       req.UserId,
+			TargetID:     req.TargetId,
+			BusChannel:   req.BusChannel,
+			StartTime:    cursor,
+			EndTime:      req.EndTime,
+			PageSize:     req.PageSize,
+			IncludeStart: includeStart,
+		})
+		return res.Data, req.EndTime, err
+	}, nil
+}
+
+func historyCSVRecord(m HistoryMessage) []string {
+	return []string{
+		m.TargetID,
+		m.FromUserID,
+		m.MsgUID,
+		strconv.FormatInt(m.MsgTime, 10),
+		m.ObjectName,
+		m.Content,
+		strconv.FormatBool(m.Expansion),
+		m.ExtraContent,
+		m.BusChannel,
+	}
+}
@@ -0,0 +1,126 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeHistory serves messages in ascending order, pageSize at a time, like the history APIs.
+func fakeHistory(messages []HistoryMessage, pageSize int, fail map[int]bool) historyFetcher {
+	calls := 0
+	return func(cursor int64, includeStart bool) ([]HistoryMessage, int64, error) {
+		calls++
+		if fail[calls] {
+			return nil, 0, errors.New("interrupted")
+		}
+		var page []HistoryMessage
+		for _, m := range messages {
+			if m.MsgTime < cursor || !includeStart && m.MsgTime == cursor {
+				continue
+			}
+			if len(page) == pageSize {
+				break
+			}
+			page = append(page, m)
+		}
+		return page, 1 << 62, nil
+	}
+}
+
+func TestHistoryWalk(t *testing.T) {
+	var messages []HistoryMessage
+	for i, ts := range []int64{10, 20, 20, 20, 30, 40, 40, 50} {
+		messages = append(messages, HistoryMessage{MsgUID: "m" + strconv.Itoa(i), MsgTime: ts})
+	}
+	var written []string
+	write := func(page []HistoryMessage) error {
+		for _, m := range page {
+			written = append(written, m.MsgUID)
+		}
+		return nil
+	}
+
+	cp, err := historyWalk(fakeHistory(messages, 3, map[int]bool{3: true}), 45, 3,
+		HistoryCheckpoint{Cursor: 10, IncludeStart: true}, write, nil)
+	if err == nil || cp.Done {
+		t.Fatalf("expected interruption, got %+v %v", cp, err)
+	}
+	cp, err = historyWalk(fakeHistory(messages, 3, nil), 45, 3, cp, write, nil)
+	if err != nil || !cp.Done {
+		t.Fatalf("unexpected result: %+v %v", cp, err)
+	}
+	if got := strings.Join(written, ","); got != "m0,m1,m2,m3,m4,m5,m6" {
+		t.Errorf("unexpected export: %s", got)
+	}
+	if cp.Exported != 7 {
+		t.Errorf("unexpected count: %d", cp.Exported)
+	}
+	// A full page at 20 can not be told apart from a millisecond holding more messages.
+	if len(cp.Truncated) != 1 || cp.Truncated[0] != 20 {
+		t.Errorf("unexpected truncation: %v", cp.Truncated)
+	}
+}
+
+func TestHistoryWalk_Truncated(t *testing.T) {
+	// Four messages share 20 with a page size of 3, so m4 can not be read.
+	var messages []HistoryMessage
+	for i, ts := range []int64{10, 20, 20, 20, 20, 30} {
+		messages = append(messages, HistoryMessage{MsgUID: "m" + strconv.Itoa(i), MsgTime: ts})
+	}
+	var written []string
+	write := func(page []HistoryMessage) error {
+		for _, m := range page {
+			written = append(written, m.MsgUID)
+		}
+		return nil
+	}
+	cp, err := historyWalk(fakeHistory(messages, 3, nil), 45, 3, HistoryCheckpoint{Cursor: 10, IncludeStart: true}, write, nil)
+	if err != nil || !cp.Done {
+		t.Fatalf("unexpected result: %+v %v", cp, err)
+	}
+	if got := strings.Join(written, ","); got != "m0,m1,m2,m3,m5" {
+		t.Errorf("unexpected export: %s", got)
+	}
+	if len(cp.Truncated) != 1 || cp.Truncated[0] != 20 {
+		t.Errorf("truncation not recorded: %v", cp.Truncated)
+	}
+}
+
+func TestHistoryExport_Validation(t *testing.T) {
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	var buf bytes.Buffer
+	if _, err := rc.HistoryExport(HistoryExportRequest{Source: "unknown", UserId: "u01", TargetId: "g01",
+		StartTime: 1, EndTime: 2}, &buf); err == nil {
+		t.Error("expected error for unknown source")
+	}
+	if _, err := rc.HistoryExport(HistoryExportRequest{Source: HistorySourceGroup, TargetId: "g01",
+		StartTime: 1, EndTime: 2}, &buf); err == nil {
+		t.Error("expected error without userId")
+	}
+}
+
+func TestRongCloud_HistoryExport(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	var buf bytes.Buffer
+	cp, err := rc.HistoryExport(HistoryExportRequest{
+		Source:    HistorySourceGroup,
+		UserId:    "u01",
+		TargetId:  "g01",
+		StartTime: 1700000000000,
+		EndTime:   1700086400000,
+		Format:    HistoryFormatCSV,
+	}, &buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(cp, buf.String())
+}
@@ -60,10 +60,10 @@ func isNetError(err error) bool {
 
 func (rc *RongCloud) httpRequest(b *httplib.BeegoHTTPRequest) (body []byte, err error) {
 	// Use the global httpClient to avoid opening too many ports
-	b.SetTransport(rc.globalTransport)
+	b.SetTransport(rc.transport())
 	resp, err := b.DoRequest()
 	if err != nil {
-		if isNetError(err) {
+		if isNetError(err) || isCircuitOpen(err) {
 			rc.ChangeURI()
 		}
 		return nil, err
@@ -91,11 +91,11 @@ func (rc *RongCloud) httpRequest(b *httplib.BeegoHTTPRequest) (body []byte, err
 // v2 api
 func (rc *RongCloud) doV2(b *httplib.BeegoHTTPRequest) (body []byte, err error) {
 	// Use the global httpClient to avoid opening too many ports
-	b.SetTransport(rc.globalTransport)
+	b.SetTransport(rc.transport())
 
 	resp, err := b.DoRequest()
 	if err != nil {
-		if isNetError(err) {
+		if isNetError(err) || isCircuitOpen(err) {
 			rc.ChangeURI()
 		}
 		return nil, err
@@ -0,0 +1,218 @@
+// Deduplication of sends repeated with the same idempotency key
+
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego/httplib"
+)
+
+const (
+	// IDEMPOTENCY_TTL Default time a send result is kept for its idempotency key
+	IDEMPOTENCY_TTL = 10 * time.Minute
+	// IDEMPOTENCY_KEY_PREFIX Prefix of the cache keys, followed by the App Key and the caller's key
+	IDEMPOTENCY_KEY_PREFIX = "rongcloud:idempotency:"
+)
+
+// idempotencyPoll Time between two checks of a key reserved by another send
+const idempotencyPoll = 50 * time.Millisecond
+
+// ErrIdempotentSendInProgress Returned when a send with the same idempotency key did not finish in time
+var ErrIdempotentSendInProgress = errors.New("a send with the same idempotency key is in progress")
+
+// ErrIdempotentSendUnknown Returned while the key of a send that timed out or lost its connection is still reserved,
+// as the server may have delivered it
+var ErrIdempotentSendUnknown = errors.New("the outcome of a send with the same idempotency key is unknown")
+
+// IdempotencyCache Storage of send results keyed by idempotency key. Share one cache, such as Redis, between
+// instances so that they deduplicate together. Implementations must be safe for concurrent use, and Add must be atomic.
+type IdempotencyCache interface {
+	// Get returns the value stored under key, and false when there is none or it expired
+	Get(key string) ([]byte, bool, error)
+	// Add stores value under key for ttl unless the key holds a value, and reports whether it was stored
+	Add(key string, value []byte, ttl time.Duration) (bool, error)
+	// Set stores value under key for ttl, replacing any value
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes key. Deleting a missing key is not an error
+	Delete(key string) error
+}
+
+// MemoryIdempotencyCache IdempotencyCache kept in process memory, used by default
+type MemoryIdempotencyCache struct {
+	lock      sync.Mutex
+	entries   map[string]idempotencyEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type idempotencyEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryIdempotencyCache creates an empty MemoryIdempotencyCache
+func NewMemoryIdempotencyCache() *MemoryIdempotencyCache {
+	return &MemoryIdempotencyCache{entries: map[string]idempotencyEntry{}, now: time.Now}
+}
+
+// get returns an entry that has not expired. The caller holds the lock.
+func (c *MemoryIdempotencyCache) get(key string) ([]byte, bool) {
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set stores an entry, removing expired entries at most once a minute. The caller holds the lock.
+func (c *MemoryIdempotencyCache) set(key string, value []byte, ttl time.Duration) {
+	now := c.now()
+	if now.Sub(c.lastSweep) >= time.Minute {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = idempotencyEntry{value: append([]byte(nil), value...), expires: now.Add(ttl)}
+}
+
+// Get implements IdempotencyCache
+func (c *MemoryIdempotencyCache) Get(key string) ([]byte, bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	value, ok := c.get(key)
+	return value, ok, nil
+}
+
+// Add implements IdempotencyCache
+func (c *MemoryIdempotencyCache) Add(key string, value []byte, ttl time.Duration) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.get(key); ok {
+		return false, nil
+	}
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+// Set implements IdempotencyCache
+func (c *MemoryIdempotencyCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.set(key, value, ttl)
+	return nil
+}
+
+// Delete implements IdempotencyCache
+func (c *MemoryIdempotencyCache) Delete(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+// idempotencyRecord Value stored under a key: a reservation while the send runs, then the response body,
+// or Unknown when the send failed without an answer from the server
+type idempotencyRecord struct {
+	Pending bool            `json:"pending,omitempty"`
+	Unknown bool            `json:"unknown,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+}
+
+// SetIdempotencyCache Replaces the cache of idempotency keys, see WithIdempotencyCache.
+func (rc *RongCloud) SetIdempotencyCache(cache IdempotencyCache, ttl time.Duration) {
+	rc.idempotencyCache, rc.idempotencyTTL = cache, ttl
+}
+
+// doIdempotent sends the request once per idempotency key. While a send holds the key, other sends with the
+// key wait for its result. The reservation expires after twice the request timeout, so that a process
+// stopping during a send does not block the key for the whole TTL. A send rejected by the server releases the key;
+// a send that timed out or failed on the network keeps it reserved until the reservation expires.
+func (rc *RongCloud) doIdempotent(b *httplib.BeegoHTTPRequest, key string) ([]byte, error) {
+	if key == "" || rc.idempotencyCache == nil {
+		return rc.do(b)
+	}
+	cache := rc.idempotencyCache
+	cacheKey := IDEMPOTENCY_KEY_PREFIX + rc.appKey + ":" + key
+	reserveTTL := 2 * time.Second * rc.timeout
+	if reserveTTL <= 0 {
+		reserveTTL = 2 * time.Second * DEFAULTTIMEOUT
+	}
+	pending, _ := json.Marshal(idempotencyRecord{Pending: true})
+	deadline := time.Now().Add(reserveTTL)
+	for {
+		added, err := cache.Add(cacheKey, pending, reserveTTL)
+		if err != nil {
+			return nil, err
+		}
+		if added {
+			body, err := rc.do(b)
+			if err != nil {
+				if idempotencyRejected(err) {
+					_ = cache.Delete(cacheKey)
+				} else if record, mErr := json.Marshal(idempotencyRecord{Unknown: true}); mErr == nil {
+					_ = cache.Set(cacheKey, record, reserveTTL)
+				}
+				return nil, err
+			}
+			// The message is sent, so a cache error only weakens deduplication and is not reported.
+			if record, err := json.Marshal(idempotencyRecord{Body: body}); err == nil {
+				_ = cache.Set(cacheKey, record, rc.idempotencyTTL)
+			}
+			return body, nil
+		}
+
+		value, ok, err := cache.Get(cacheKey)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			var record idempotencyRecord
+			if err := json.Unmarshal(value, &record); err != nil {
+				return nil, err
+			}
+			if record.Unknown {
+				return nil, ErrIdempotentSendUnknown
+			}
+			if !record.Pending {
+				return record.Body, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrIdempotentSendInProgress
+		}
+		if ok {
+			time.Sleep(idempotencyPoll)
+		}
+	}
+}
+
+// idempotencyRejected reports whether a failed send was certainly not processed: the server answered with an
+// error code, or the request was not sent because its circuit is open.
+func idempotencyRejected(err error) bool {
+	if _, ok := err.(CodeResult); ok {
+		return true
+	}
+	return isCircuitOpen(err)
+}
+
+// idempotencyChunks returns the options of each request of a send split into several requests,
+// with the idempotency key followed by the number of the request, so that every request keeps its own result.
+func idempotencyChunks(options []MsgOption) func() []MsgOption {
+	key := modifyMsgOptions(options).idempotencyKey
+	n := 0
+	return func() []MsgOption {
+		if key == "" {
+			return options
+		}
+		n++
+		return append(options[:len(options):len(options)], WithIdempotencyKey(fmt.Sprintf("%s/%d", key, n)))
+	}
+}
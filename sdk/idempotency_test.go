@@ -0,0 +1,175 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// idempotencyServer answers private sends with a new messageUID per request, failing while fail is set
+func idempotencyServer(fail *int32, delay time.Duration) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		time.Sleep(delay)
+		if atomic.LoadInt32(fail) != 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code": 1000, "errorMessage": "Internal error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "messageUID": "uid-` + string(rune('0'+n)) + `"}`))
+	}))
+	return server, &calls
+}
+
+func TestRongCloud_IdempotentSend(t *testing.T) {
+	var fail int32 = 1
+	server, calls := idempotencyServer(&fail, 0)
+	defer server.Close()
+	client := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	send := func(key string) (MessageResult, error) {
+		return client.PrivateSend("u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hi"}, "", "", 0, 0, 1, 0, 0,
+			WithIdempotencyKey(key))
+	}
+
+	// A failed send releases the key.
+	if _, err := send("order-1"); err == nil {
+		t.Fatal("send did not fail")
+	}
+	atomic.StoreInt32(&fail, 0)
+	first, err := send("order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := send("order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.MessageUID != "uid-2" || again.MessageUID != first.MessageUID || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("first = %+v, again = %+v, calls = %d", first, again, *calls)
+	}
+	if other, _ := send("order-2"); other.MessageUID != "uid-3" {
+		t.Errorf("other key = %+v", other)
+	}
+	// Sends without a key are never deduplicated.
+	if _, err := client.PrivateSend("u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hi"}, "", "", 0, 0, 1, 0, 0); err != nil || atomic.LoadInt32(calls) != 4 {
+		t.Errorf("err = %v, calls = %d", err, *calls)
+	}
+}
+
+func TestRongCloud_IdempotentSendConnectionLost(t *testing.T) {
+	var calls, drop int32 = 0, 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&drop) != 0 {
+			// The send may have been processed, but the answer is lost.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "messageUID": "uid-2"}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cache := NewMemoryIdempotencyCache()
+	cache.now = func() time.Time { return now }
+	client := newRongCloud("key", "secret", NewRegion(server.URL, ""), WithIdempotencyCache(cache, time.Minute))
+	send := func() (MessageResult, error) {
+		return client.PrivateSend("u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hi"}, "", "", 0, 0, 1, 0, 0,
+			WithIdempotencyKey("order-1"))
+	}
+
+	if _, err := send(); err == nil {
+		t.Fatal("send did not fail")
+	}
+	atomic.StoreInt32(&drop, 0)
+	if _, err := send(); err != ErrIdempotentSendUnknown || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+	// Once the reservation expires the key can be sent again.
+	now = now.Add(2 * time.Second * DEFAULTTIMEOUT)
+	if res, err := send(); err != nil || res.MessageUID != "uid-2" || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("res = %+v, err = %v, calls = %d", res, err, calls)
+	}
+}
+
+func TestRongCloud_IdempotentSendSharedCache(t *testing.T) {
+	var fail int32
+	server, calls := idempotencyServer(&fail, 20*time.Millisecond)
+	defer server.Close()
+
+	// Two instances of the same app sharing one cache send concurrently.
+	cache := NewMemoryIdempotencyCache()
+	clients := []*RongCloud{
+		newRongCloud("key", "secret", NewRegion(server.URL, ""), WithIdempotencyCache(cache, time.Minute)),
+		newRongCloud("key", "secret", NewRegion(server.URL, ""), WithIdempotencyCache(cache, time.Minute)),
+	}
+	var wg sync.WaitGroup
+	results := make([]MessageResult, 6)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = clients[i%2].GroupSend("u01", []string{"g01"}, nil, "RC:TxtMsg", &TXTMsg{Content: "hi"}, "", "", 1, 0,
+				WithIdempotencyKey("order-1"))
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if atomic.LoadInt32(calls) != 1 {
+		t.Errorf("calls = %d", *calls)
+	}
+	for _, res := range results {
+		if res.MessageUID != "uid-1" {
+			t.Errorf("results = %+v", results)
+			break
+		}
+	}
+}
+
+func TestMemoryIdempotencyCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewMemoryIdempotencyCache()
+	cache.now = func() time.Time { return now }
+
+	if added, _ := cache.Add("k", []byte("a"), time.Minute); !added {
+		t.Fatal("first add refused")
+	}
+	if added, _ := cache.Add("k", []byte("b"), time.Minute); added {
+		t.Error("second add accepted")
+	}
+	if value, ok, _ := cache.Get("k"); !ok || string(value) != "a" {
+		t.Errorf("get = %s, %v", value, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok, _ := cache.Get("k"); ok {
+		t.Error("expired value returned")
+	}
+	if added, _ := cache.Add("k", []byte("c"), time.Minute); !added {
+		t.Error("add after expiry refused")
+	}
+	_ = cache.Delete("k")
+	if _, ok, _ := cache.Get("k"); ok {
+		t.Error("deleted value returned")
+	}
+}
+
+func TestIdempotencyChunks(t *testing.T) {
+	next := idempotencyChunks([]MsgOption{WithMsgDisablePush(true), WithIdempotencyKey("batch")})
+	for _, want := range []string{"batch/1", "batch/2"} {
+		opts := modifyMsgOptions(next())
+		if opts.idempotencyKey != want || !opts.disablePush {
+			t.Errorf("options = %+v, want key %s", opts, want)
+		}
+	}
+	if opts := modifyMsgOptions(idempotencyChunks(nil)()); opts.idempotencyKey != "" {
+		t.Errorf("key without option = %s", opts.idempotencyKey)
+	}
+}
@@ -383,6 +383,7 @@ type msgOptions struct {
 	extraContent         string
 	isCounted            int
 	disableUpdateLastMsg bool
+	idempotencyKey       string
 }
 
 // MsgOption interface functions
@@ -491,6 +492,19 @@ func WithMsgIsCounted(isCounted int) MsgOption {
 	}
 }
 
+// WithIdempotencyKey Caller's key of a send. A send repeated with the same key within the idempotency TTL returns the
+// result of the first send instead of sending again, see WithIdempotencyCache. A send rejected by the server with an
+// error code releases the key. A send that timed out or failed on the network may have been delivered, so the key
+// stays reserved for twice the request timeout and repeated sends return ErrIdempotentSendUnknown until then.
+// The key applies to the sends that take MsgOption: PrivateSend, PrivateStatusSend, PrivateSendTemplate, GroupSend,
+// GroupStatusSend, GroupSendMention, OnlineBroadcast, SystemSend, SystemBroadcast and SystemSendTemplate.
+// ChatRoomSend, ChatRoomBroadcast, PushSend and the ultra group sends take no options and are never deduplicated.
+func WithIdempotencyKey(key string) MsgOption {
+	return func(options *msgOptions) {
+		options.idempotencyKey = key
+	}
+}
+
 // Modify default values
 func modifyMsgOptions(options []MsgOption) msgOptions {
 	// Default values
@@ -1134,7 +1148,7 @@ func (rc *RongCloud) PrivateSend(senderID string, targetID []string, objectName
 		req.Param("disableUpdateLastMsg", strconv.FormatBool(extraOptins.disableUpdateLastMsg))
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1188,7 +1202,7 @@ func (rc *RongCloud) PrivateStatusSend(senderID string, targetID []string, objec
 		req.Param("busChannel", extraOptins.busChannel)
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1318,7 +1332,7 @@ func (rc *RongCloud) PrivateSendTemplate(senderID, objectName string, template T
 		return result, err
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1402,7 +1416,7 @@ func (rc *RongCloud) GroupSend(senderID string, targetID, userID []string, objec
 		req.Param("extraContent", extraOptins.extraContent)
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1454,7 +1468,7 @@ func (rc *RongCloud) GroupStatusSend(senderID string, toGroupIds []string, objec
 		req.Param("busChannel", extraOptins.busChannel)
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1576,7 +1590,7 @@ func (rc *RongCloud) GroupSendMention(senderID string, targetID []string, object
 		req.Param("disableUpdateLastMsg", strconv.FormatBool(extraOptins.disableUpdateLastMsg))
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1703,7 +1717,7 @@ func (rc *RongCloud) OnlineBroadcast(fromUserId string, objectName string, conte
 		req.Param("disableUpdateLastMsg", strconv.FormatBool(extraOptins.disableUpdateLastMsg))
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1775,7 +1789,7 @@ func (rc *RongCloud) SystemSend(senderID string, targetID []string, objectName s
 		req.Param("disableUpdateLastMsg", strconv.FormatBool(extraOptins.disableUpdateLastMsg))
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1828,7 +1842,7 @@ func (rc *RongCloud) SystemBroadcast(senderID, objectName string, msg rcMsg, opt
 		req.Param("disableUpdateLastMsg", strconv.FormatBool(extraOptins.disableUpdateLastMsg))
 	}
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
@@ -1901,7 +1915,7 @@ func (rc *RongCloud) SystemSendTemplate(senderID, objectName string, template TX
 
 	_, _ = req.JSONBody(param)
 
-	resp, err := rc.do(req)
+	resp, err := rc.doIdempotent(req, extraOptins.idempotencyKey)
 	if err != nil {
 		rc.urlError(err)
 		return result, err
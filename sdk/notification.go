@@ -0,0 +1,620 @@
+// Notification preferences of a user
+
+package sdk
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// NotificationChangeQuietHours Global Do Not Disturb period of the user
+	NotificationChangeQuietHours = "quietHours"
+	// NotificationChangeTypeLevel Do Not Disturb level of a conversation type
+	NotificationChangeTypeLevel = "typeLevel"
+	// NotificationChangeConversationLevel Do Not Disturb level of a conversation or ultra group channel
+	NotificationChangeConversationLevel = "conversationLevel"
+	// NotificationChangeConversationTop Pinned state of a conversation
+	NotificationChangeConversationTop = "conversationTop"
+	// NotificationChangeUltraGroupDefault Default Do Not Disturb level of an ultra group or channel, shared by all members
+	NotificationChangeUltraGroupDefault = "ultraGroupDefault"
+)
+
+// NotificationQuietHours Global Do Not Disturb period, see UserBlockPushPeriodSet
+type NotificationQuietHours struct {
+	StartTime string `json:"startTime"` // Start time, format HH:MM:SS
+	Period    int    `json:"period"`    // Duration in minutes, 0 removes the period
+	Level     int    `json:"level"`     // Do Not Disturb level during the period, 0 means the default level 1
+}
+
+// NotificationTarget Identifies a conversation, or an ultra group channel when BusChannel is set
+type NotificationTarget struct {
+	Type       ConversationType `json:"conversationType"`     // Conversation type: 1 one-to-one chat, 3 group chat, 6 system, 10 ultra group
+	TargetId   string           `json:"targetId"`             // User ID, group ID, system target ID or ultra group ID
+	BusChannel string           `json:"busChannel,omitempty"` // Ultra group channel ID
+}
+
+// ConversationPreference Do Not Disturb level and pinned state of one conversation
+type ConversationPreference struct {
+	NotificationTarget
+	UnPushLevel *int  `json:"unpushLevel,omitempty"` // One of the ConversationUnPushLevel* constants, nil when not managed
+	Top         *bool `json:"top,omitempty"`         // Pinned state, nil when not managed. The server offers no query, so a read leaves it nil
+}
+
+// UltraGroupDefaultPreference Default Do Not Disturb level of an ultra group or channel, see UGNotDisturbSet
+type UltraGroupDefaultPreference struct {
+	GroupId     string `json:"groupId"`              // Ultra group ID
+	BusChannel  string `json:"busChannel,omitempty"` // Channel ID, empty for the whole ultra group
+	UnPushLevel int    `json:"unpushLevel"`          // One of the UGUnPushLevel* constants
+}
+
+// NotificationPreferences All notification related settings of a user as one document.
+// Every part is optional: nil QuietHours, a missing TypeLevels key or an unlisted conversation is left untouched,
+// so a document only describes the settings the caller manages.
+type NotificationPreferences struct {
+	UserId             string                        `json:"userId"`
+	QuietHours         *NotificationQuietHours       `json:"quietHours,omitempty"`
+	TypeLevels         map[ConversationType]int      `json:"typeLevels,omitempty"`
+	Conversations      []ConversationPreference      `json:"conversations,omitempty"`
+	UltraGroupDefaults []UltraGroupDefaultPreference `json:"ultraGroupDefaults,omitempty"`
+}
+
+// NotificationChange A single setting that differs between two NotificationPreferences
+type NotificationChange struct {
+	Kind        string                  `json:"kind"`                 // One of the NotificationChange* constants
+	Target      NotificationTarget      `json:"target,omitempty"`     // Conversation of conversationLevel and conversationTop changes, or the ultra group of ultraGroupDefault changes
+	UnPushLevel int                     `json:"unpushLevel"`          // New level of typeLevel, conversationLevel and ultraGroupDefault changes
+	Top         bool                    `json:"top"`                  // New pinned state of conversationTop changes
+	QuietHours  *NotificationQuietHours `json:"quietHours,omitempty"` // New period of quietHours changes, a zero Period removes it
+}
+
+// NotificationPreferencesGet Reads the current value of every setting present in scope.
+// Pinned states cannot be queried and are returned as nil.
+/*
+*@param  userId: User ID.
+*@param  scope: Settings to read. Only keys are used, values are ignored. QuietHours is read when it is not nil.
+*
+*@return NotificationPreferences, error
+ */
+func (rc *RongCloud) NotificationPreferencesGet(userId string, scope NotificationPreferences) (NotificationPreferences, error) {
+	prefs := NotificationPreferences{UserId: userId}
+	if userId == "" {
+		return prefs, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+
+	if scope.QuietHours != nil {
+		period, err := rc.UserBlockPushPeriodGet(userId)
+		if err != nil {
+			return prefs, err
+		}
+		prefs.QuietHours = &NotificationQuietHours{
+			StartTime: period.Data.StartTime,
+			Period:    period.Data.Period,
+			Level:     period.Data.Level,
+		}
+	}
+
+	if len(scope.TypeLevels) > 0 {
+		prefs.TypeLevels = make(map[ConversationType]int, len(scope.TypeLevels))
+		for ct := range scope.TypeLevels {
+			level, err := rc.ConversationTypeNotificationGet(ct, userId)
+			if err != nil {
+				return prefs, err
+			}
+			prefs.TypeLevels[ct] = level
+		}
+	}
+
+	for _, c := range scope.Conversations {
+		level, err := rc.ConversationNotificationGet(c.Type, userId, c.TargetId, c.BusChannel)
+		if err != nil {
+			return prefs, err
+		}
+		prefs.Conversations = append(prefs.Conversations, ConversationPreference{
+			NotificationTarget: c.NotificationTarget,
+			UnPushLevel:        &level,
+		})
+	}
+
+	for _, d := range scope.UltraGroupDefaults {
+		res, _, err := rc.UltraGroup().NotDisturbQuery(d.GroupId, d.BusChannel)
+		if err != nil {
+			return prefs, err
+		}
+		prefs.UltraGroupDefaults = append(prefs.UltraGroupDefaults, UltraGroupDefaultPreference{
+			GroupId:     d.GroupId,
+			BusChannel:  d.BusChannel,
+			UnPushLevel: res.UnPushLevel,
+		})
+	}
+
+	return prefs, nil
+}
+
+// NotificationPreferencesDiff Computes the changes that turn current into desired.
+// Only settings present in desired are compared: a conversation with a nil level or pinned state leaves it untouched.
+// A pinned state is always reported when current does not know it, since the server cannot be queried for it.
+/*
+*@param  current: The current settings, usually from NotificationPreferencesGet.
+*@param  desired: The settings the caller wants.
+*
+*@return []NotificationChange
+ */
+func NotificationPreferencesDiff(current, desired NotificationPreferences) []NotificationChange {
+	var changes []NotificationChange
+
+	if desired.QuietHours != nil && !equalQuietHours(current.QuietHours, desired.QuietHours) {
+		q := *desired.QuietHours
+		changes = append(changes, NotificationChange{Kind: NotificationChangeQuietHours, QuietHours: &q})
+	}
+
+	types := make([]int, 0, len(desired.TypeLevels))
+	for ct := range desired.TypeLevels {
+		types = append(types, int(ct))
+	}
+	sort.Ints(types)
+	for _, t := range types {
+		ct := ConversationType(t)
+		level := desired.TypeLevels[ct]
+		if cur, ok := current.TypeLevels[ct]; ok && cur == level {
+			continue
+		}
+		changes = append(changes, NotificationChange{
+			Kind:        NotificationChangeTypeLevel,
+			Target:      NotificationTarget{Type: ct},
+			UnPushLevel: level,
+		})
+	}
+
+	currentConversations := make(map[NotificationTarget]ConversationPreference, len(current.Conversations))
+	for _, c := range current.Conversations {
+		currentConversations[c.NotificationTarget] = c
+	}
+	for _, c := range desired.Conversations {
+		cur := currentConversations[c.NotificationTarget]
+		if c.UnPushLevel != nil && (cur.UnPushLevel == nil || *cur.UnPushLevel != *c.UnPushLevel) {
+			changes = append(changes, NotificationChange{
+				Kind:        NotificationChangeConversationLevel,
+				Target:      c.NotificationTarget,
+				UnPushLevel: *c.UnPushLevel,
+			})
+		}
+		if c.Top != nil && (cur.Top == nil || *cur.Top != *c.Top) {
+			changes = append(changes, NotificationChange{
+				Kind:   NotificationChangeConversationTop,
+				Target: c.NotificationTarget,
+				Top:    *c.Top,
+			})
+		}
+	}
+
+	currentDefaults := make(map[NotificationTarget]int, len(current.UltraGroupDefaults))
+	for _, d := range current.UltraGroupDefaults {
+		currentDefaults[ultraGroupDefaultTarget(d)] = d.UnPushLevel
+	}
+	for _, d := range desired.UltraGroupDefaults {
+		target := ultraGroupDefaultTarget(d)
+		if cur, ok := currentDefaults[target]; ok && cur == d.UnPushLevel {
+			continue
+		}
+		changes = append(changes, NotificationChange{
+			Kind:        NotificationChangeUltraGroupDefault,
+			Target:      target,
+			UnPushLevel: d.UnPushLevel,
+		})
+	}
+
+	return changes
+}
+
+// NotificationPreferencesApply Reads the settings present in desired, then writes only those that differ.
+/*
+*@param  desired: The settings the caller wants, desired.UserId is required.
+*
+*@return []NotificationChange: The changes that were applied before an error, if any.
+*@return error
+ */
+func (rc *RongCloud) NotificationPreferencesApply(desired NotificationPreferences) ([]NotificationChange, error) {
+	current, err := rc.NotificationPreferencesGet(desired.UserId, desired)
+	if err != nil {
+		return nil, err
+	}
+	changes := NotificationPreferencesDiff(current, desired)
+	for i, change := range changes {
+		if err := rc.applyNotificationChange(desired.UserId, change); err != nil {
+			return changes[:i], err
+		}
+	}
+	return changes, nil
+}
+
+func (rc *RongCloud) applyNotificationChange(userId string, change NotificationChange) error {
+	switch change.Kind {
+	case NotificationChangeQuietHours:
+		q := change.QuietHours
+		if q == nil || q.Period == 0 {
+			return rc.UserBlockPushPeriodDelete(userId)
+		}
+		level := ""
+		if q.Level != 0 {
+			level = strconv.Itoa(q.Level)
+		}
+		return rc.UserBlockPushPeriodSet(userId, q.StartTime, strconv.Itoa(q.Period), level)
+	case NotificationChangeTypeLevel:
+		return rc.ConversationTypeNotificationSet(change.Target.Type, userId, change.UnPushLevel)
+	case NotificationChangeConversationLevel:
+		isMuted := 1
+		if change.UnPushLevel == ConversationUnPushLevelNotSet || change.UnPushLevel == ConversationUnPushLevelAllMessage {
+			isMuted = 0
+		}
+		t := change.Target
+		return rc.ConversationNotificationSet(t.Type, userId, t.TargetId, t.BusChannel, isMuted, change.UnPushLevel)
+	case NotificationChangeConversationTop:
+		t := change.Target
+		return rc.ConversationTop(t.Type, userId, t.TargetId, strconv.FormatBool(change.Top))
+	case NotificationChangeUltraGroupDefault:
+		_, err := rc.UltraGroup().NotDisturbSet(change.Target.TargetId, change.Target.BusChannel, change.UnPushLevel)
+		return err
+	default:
+		return RCErrorNew(1002, "Paramer 'kind' was wrong")
+	}
+}
+
+func ultraGroupDefaultTarget(d UltraGroupDefaultPreference) NotificationTarget {
+	return NotificationTarget{Type: ConversationTypeUG, TargetId: d.GroupId, BusChannel: d.BusChannel}
+}
+
+func equalQuietHours(a, b *NotificationQuietHours) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Period == 0 && b.Period == 0 {
+		return true
+	}
+	levelA, levelB := a.Level, b.Level
+	if levelA == 0 {
+		levelA = 1
+	}
+	if levelB == 0 {
+		levelB = 1
+	}
+	return a.StartTime == b.StartTime && a.Period == b.Period && levelA == levelB
+}
+
+// MentionState How a message mentions the recipient
+type MentionState int
+
+const (
+	MentionNone MentionState = iota // MentionNone the message mentions nobody or only other users
+	MentionUser                     // MentionUser the message @ mentions the recipient
+	MentionAll                      // MentionAll the message @ mentions all members
+)
+
+const (
+	// NotificationRuleQuietHours The global Do Not Disturb period of the user decided
+	NotificationRuleQuietHours = "quietHours"
+	// NotificationRuleChannel The setting of the user for the ultra group channel decided
+	NotificationRuleChannel = "channel"
+	// NotificationRuleConversation The setting of the user for the conversation decided
+	NotificationRuleConversation = "conversation"
+	// NotificationRuleType The setting of the user for the conversation type decided
+	NotificationRuleType = "conversationType"
+	// NotificationRuleChannelDefault The default level of the ultra group channel decided
+	NotificationRuleChannelDefault = "channelDefault"
+	// NotificationRuleGroupDefault The default level of the ultra group decided
+	NotificationRuleGroupDefault = "groupDefault"
+	// NotificationRuleNone Nothing is set, all messages are pushed
+	NotificationRuleNone = "none"
+)
+
+// NotificationDecision Effective push decision for a message
+type NotificationDecision struct {
+	Push        bool   `json:"push"`        // Whether the recipient is pushed
+	Rule        string `json:"rule"`        // One of the NotificationRule* constants, the layer that decided
+	UnPushLevel int    `json:"unpushLevel"` // Level of the deciding layer
+	Reason      string `json:"reason"`      // Human readable explanation
+}
+
+// ResolveNotification Computes the effective push decision from settings that were already read.
+// Layers are checked from the most to the least specific: global Do Not Disturb period, ultra group channel,
+// conversation, conversation type, ultra group channel default and ultra group default.
+// A layer that is not set (level 0) defers to the next one.
+/*
+*@param  prefs: Settings of the recipient, as returned by NotificationPreferencesGet for the target.
+*@param  target: The conversation of the message, BusChannel set for ultra group channels.
+*@param  mention: How the message mentions the recipient.
+*@param  now: Time the message is sent, in the time zone the Do Not Disturb period is expressed in.
+*
+*@return NotificationDecision
+ */
+func ResolveNotification(prefs NotificationPreferences, target NotificationTarget, mention MentionState, now time.Time) NotificationDecision {
+	if q := prefs.QuietHours; q != nil && q.Period > 0 && inQuietHours(*q, now) {
+		level := q.Level
+		if level == ConversationUnPushLevelNotSet {
+			level = ConversationUnPushLevelAtMessage
+		}
+		return decideNotification(NotificationRuleQuietHours, level, mention)
+	}
+
+	levels := map[NotificationTarget]int{}
+	for _, c := range prefs.Conversations {
+		if c.UnPushLevel != nil {
+			levels[c.NotificationTarget] = *c.UnPushLevel
+		}
+	}
+	defaults := map[NotificationTarget]int{}
+	for _, d := range prefs.UltraGroupDefaults {
+		defaults[ultraGroupDefaultTarget(d)] = d.UnPushLevel
+	}
+	conversation := NotificationTarget{Type: target.Type, TargetId: target.TargetId}
+
+	if target.BusChannel != "" {
+		if level := levels[target]; level != ConversationUnPushLevelNotSet {
+			return decideNotification(NotificationRuleChannel, level, mention)
+		}
+	}
+	if level := levels[conversation]; level != ConversationUnPushLevelNotSet {
+		return decideNotification(NotificationRuleConversation, level, mention)
+	}
+	if level := prefs.TypeLevels[target.Type]; level != ConversationUnPushLevelNotSet {
+		return decideNotification(NotificationRuleType, level, mention)
+	}
+	if target.Type == ConversationTypeUG {
+		if target.BusChannel != "" {
+			if level := defaults[target]; level != UGUnPushLevelNotSet {
+				return decideNotification(NotificationRuleChannelDefault, level, mention)
+			}
+		}
+		if level := defaults[conversation]; level != UGUnPushLevelNotSet {
+			return decideNotification(NotificationRuleGroupDefault, level, mention)
+		}
+	}
+	return NotificationDecision{Push: true, Rule: NotificationRuleNone, Reason: "no Do Not Disturb setting applies"}
+}
+
+func decideNotification(rule string, level int, mention MentionState) NotificationDecision {
+	d := NotificationDecision{Rule: rule, UnPushLevel: level}
+	switch level {
+	case ConversationUnPushLevelAllMessage:
+		d.Push, d.Reason = true, "all messages are pushed"
+	case ConversationUnPushLevelAtMessage:
+		d.Push = mention != MentionNone
+		d.Reason = "only @ messages are pushed"
+	case ConversationUnPushLevelAtUser:
+		d.Push = mention == MentionUser
+		d.Reason = "only messages that @ the user are pushed"
+	case ConversationUnPushLevelAtAllGroupMembers:
+		d.Push = mention == MentionAll
+		d.Reason = "only messages that @ all members are pushed"
+	case ConversationUnPushLevelNotRecv:
+		d.Push, d.Reason = false, "no messages are pushed"
+	default:
+		d.Push, d.Reason = true, "unknown level "+strconv.Itoa(level)+", pushed"
+	}
+	d.Reason = rule + ": " + d.Reason
+	return d
+}
+
+// inQuietHours reports whether now falls in the period starting daily at q.StartTime.
+func inQuietHours(q NotificationQuietHours, now time.Time) bool {
+	start, err := time.Parse("15:04:05", q.StartTime)
+	if err != nil {
+		return false
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	begin := startOfDay.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute + time.Duration(start.Second())*time.Second)
+	length := time.Duration(q.Period) * time.Minute
+	// The period may have started yesterday and still be running.
+	for _, b := range []time.Time{begin.AddDate(0, 0, -1), begin} {
+		if !now.Before(b) && now.Before(b.Add(length)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationResolver Resolves effective push decisions, caching the settings it reads
+type NotificationResolver struct {
+	rc       *RongCloud
+	ttl      time.Duration
+	location *time.Location
+	now      func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]notificationCacheEntry
+}
+
+type notificationCacheEntry struct {
+	level   int
+	quiet   *NotificationQuietHours
+	expires time.Time
+}
+
+// NotificationResolverOption Functional option of NewNotificationResolver
+type NotificationResolverOption func(*NotificationResolver)
+
+// WithResolverTTL sets how long settings are cached, default 60 seconds. 0 disables the cache
+func WithResolverTTL(ttl time.Duration) NotificationResolverOption {
+	return func(r *NotificationResolver) {
+		r.ttl = ttl
+	}
+}
+
+// WithResolverLocation sets the time zone Do Not Disturb periods are expressed in, default time.Local
+func WithResolverLocation(location *time.Location) NotificationResolverOption {
+	return func(r *NotificationResolver) {
+		r.location = location
+	}
+}
+
+// WithResolverClock sets the clock used for Do Not Disturb periods and cache expiry
+func WithResolverClock(now func() time.Time) NotificationResolverOption {
+	return func(r *NotificationResolver) {
+		r.now = now
+	}
+}
+
+// NewNotificationResolver creates a NotificationResolver
+func (rc *RongCloud) NewNotificationResolver(options ...NotificationResolverOption) *NotificationResolver {
+	r := &NotificationResolver{
+		rc:       rc,
+		ttl:      60 * time.Second,
+		location: time.Local,
+		now:      time.Now,
+		cache:    map[string]notificationCacheEntry{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Resolve Fetches the settings relevant to the message and returns the effective push decision for the recipient.
+/*
+*@param  userId: Recipient user ID.
+*@param  target: The conversation of the message, from the recipient's point of view. BusChannel set for ultra group channels.
+*@param  mention: How the message mentions the recipient.
+*
+*@return NotificationDecision, error
+ */
+func (r *NotificationResolver) Resolve(userId string, target NotificationTarget, mention MentionState) (NotificationDecision, error) {
+	prefs, err := r.preferences(userId, target)
+	if err != nil {
+		return NotificationDecision{}, err
+	}
+	return ResolveNotification(prefs, target, mention, r.now().In(r.location)), nil
+}
+
+// Invalidate Drops the cached settings of a user, e.g. after NotificationPreferencesApply.
+// Ultra group defaults are shared by all members and are kept, see InvalidateUltraGroup
+func (r *NotificationResolver) Invalidate(userId string) {
+	r.invalidate(userId + "\x00")
+}
+
+// InvalidateUltraGroup Drops the cached default levels of an ultra group and its channels, e.g. after UGNotDisturbSet
+func (r *NotificationResolver) InvalidateUltraGroup(groupId string) {
+	r.invalidate(ultraGroupDefaultKey(groupId, ""))
+}
+
+// invalidate drops the cached entries whose key starts with prefix.
+func (r *NotificationResolver) invalidate(prefix string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for key := range r.cache {
+		if strings.HasPrefix(key, prefix) {
+			delete(r.cache, key)
+		}
+	}
+}
+
+func (r *NotificationResolver) preferences(userId string, target NotificationTarget) (NotificationPreferences, error) {
+	prefs := NotificationPreferences{UserId: userId, TypeLevels: map[ConversationType]int{}}
+	if userId == "" {
+		return prefs, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	if target.TargetId == "" {
+		return prefs, RCErrorNew(1002, "Paramer 'targetId' is required")
+	}
+
+	entry, err := r.cached(userId+"\x00quiet", func() (notificationCacheEntry, error) {
+		period, err := r.rc.UserBlockPushPeriodGet(userId)
+		return notificationCacheEntry{quiet: &NotificationQuietHours{
+			StartTime: period.Data.StartTime,
+			Period:    period.Data.Period,
+			Level:     period.Data.Level,
+		}}, err
+	})
+	if err != nil {
+		return prefs, err
+	}
+	prefs.QuietHours = entry.quiet
+
+	conversations := []NotificationTarget{{Type: target.Type, TargetId: target.TargetId}}
+	if target.BusChannel != "" {
+		conversations = append(conversations, target)
+	}
+	for _, t := range conversations {
+		t := t
+		entry, err := r.cached(userId+"\x00conversation\x00"+notificationTargetKey(t), func() (notificationCacheEntry, error) {
+			level, err := r.rc.ConversationNotificationGet(t.Type, userId, t.TargetId, t.BusChannel)
+			return notificationCacheEntry{level: level}, err
+		})
+		if err != nil {
+			return prefs, err
+		}
+		level := entry.level
+		prefs.Conversations = append(prefs.Conversations, ConversationPreference{NotificationTarget: t, UnPushLevel: &level})
+	}
+
+	entry, err = r.cached(userId+"\x00type\x00"+strconv.Itoa(int(target.Type)), func() (notificationCacheEntry, error) {
+		level, err := r.rc.ConversationTypeNotificationGet(target.Type, userId)
+		return notificationCacheEntry{level: level}, err
+	})
+	if err != nil {
+		return prefs, err
+	}
+	prefs.TypeLevels[target.Type] = entry.level
+
+	if target.Type == ConversationTypeUG {
+		channels := []string{""}
+		if target.BusChannel != "" {
+			channels = append(channels, target.BusChannel)
+		}
+		for _, channel := range channels {
+			channel := channel
+			entry, err := r.cached(ultraGroupDefaultKey(target.TargetId, channel), func() (notificationCacheEntry, error) {
+				res, _, err := r.rc.UltraGroup().NotDisturbQuery(target.TargetId, channel)
+				if err != nil {
+					return notificationCacheEntry{}, err
+				}
+				return notificationCacheEntry{level: res.UnPushLevel}, nil
+			})
+			if err != nil {
+				return prefs, err
+			}
+			prefs.UltraGroupDefaults = append(prefs.UltraGroupDefaults, UltraGroupDefaultPreference{
+				GroupId:     target.TargetId,
+				BusChannel:  channel,
+				UnPushLevel: entry.level,
+			})
+		}
+	}
+
+	return prefs, nil
+}
+
+// cached returns the entry for key, calling load when it is missing or expired.
+func (r *NotificationResolver) cached(key string, load func() (notificationCacheEntry, error)) (notificationCacheEntry, error) {
+	now := r.now()
+	r.lock.Lock()
+	entry, ok := r.cache[key]
+	r.lock.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry, nil
+	}
+
+	entry, err := load()
+	if err != nil {
+		return entry, err
+	}
+	if r.ttl > 0 {
+		entry.expires = now.Add(r.ttl)
+		r.lock.Lock()
+		r.cache[key] = entry
+		r.lock.Unlock()
+	}
+	return entry, nil
+}
+
+// ultraGroupDefaultKey returns the cache key of an ultra group default level. The empty user ID keeps
+// it apart from the per-user keys, and a key with an empty channel prefixes those of every channel.
+func ultraGroupDefaultKey(groupId, busChannel string) string {
+	return "\x00default\x00" + groupId + "\x00" + busChannel
+}
+
+func notificationTargetKey(t NotificationTarget) string {
+	return strconv.Itoa(int(t.Type)) + "\x00" + t.TargetId + "\x00" + t.BusChannel
+}
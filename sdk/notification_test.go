@@ -0,0 +1,205 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNotificationPreferencesDiff(t *testing.T) {
+	top := true
+	level := func(l int) *int { return &l }
+	current := NotificationPreferences{
+		UserId:     "u01",
+		QuietHours: &NotificationQuietHours{StartTime: "23:00:00", Period: 480, Level: 1},
+		TypeLevels: map[ConversationType]int{ConversationTypeGroup: ConversationUnPushLevelNotSet},
+		Conversations: []ConversationPreference{
+			{NotificationTarget: NotificationTarget{Type: ConversationTypePrivate, TargetId: "u02"}, UnPushLevel: level(ConversationUnPushLevelNotRecv)},
+		},
+		UltraGroupDefaults: []UltraGroupDefaultPreference{{GroupId: "ug01", BusChannel: "c01", UnPushLevel: UGUnPushLevelAtMessage}},
+	}
+	desired := NotificationPreferences{
+		UserId:     "u01",
+		QuietHours: &NotificationQuietHours{StartTime: "23:00:00", Period: 480},
+		TypeLevels: map[ConversationType]int{ConversationTypeGroup: ConversationUnPushLevelAtMessage},
+		Conversations: []ConversationPreference{
+			{NotificationTarget: NotificationTarget{Type: ConversationTypePrivate, TargetId: "u02"}, UnPushLevel: level(ConversationUnPushLevelNotRecv), Top: &top},
+			{NotificationTarget: NotificationTarget{Type: ConversationTypeUG, TargetId: "ug01", BusChannel: "c01"}, UnPushLevel: level(ConversationUnPushLevelAtUser)},
+		},
+		UltraGroupDefaults: []UltraGroupDefaultPreference{{GroupId: "ug01", BusChannel: "c01", UnPushLevel: UGUnPushLevelAtMessage}},
+	}
+
+	changes := NotificationPreferencesDiff(current, desired)
+	kinds := []string{NotificationChangeTypeLevel, NotificationChangeConversationTop, NotificationChangeConversationLevel}
+	if len(changes) != len(kinds) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	for i, kind := range kinds {
+		if changes[i].Kind != kind {
+			t.Errorf("change %d: expected %s, got %s", i, kind, changes[i].Kind)
+		}
+	}
+	if changes[2].Target.BusChannel != "c01" || changes[2].UnPushLevel != ConversationUnPushLevelAtUser {
+		t.Errorf("unexpected channel change: %+v", changes[2])
+	}
+
+	if changes = NotificationPreferencesDiff(desired, desired); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	// A conversation that only sets Top leaves its level untouched.
+	topOnly := NotificationPreferences{UserId: "u01", Conversations: []ConversationPreference{
+		{NotificationTarget: NotificationTarget{Type: ConversationTypeGroup, TargetId: "g01"}, Top: &top},
+	}}
+	changes = NotificationPreferencesDiff(NotificationPreferences{UserId: "u01"}, topOnly)
+	if len(changes) != 1 || changes[0].Kind != NotificationChangeConversationTop {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestRongCloud_NotificationPreferencesGet(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{"code": 200, "isMuted": 1}`))
+	}))
+	defer server.Close()
+
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	target := NotificationTarget{Type: ConversationTypeGroup, TargetId: "g01"}
+	prefs, err := rc.NotificationPreferencesGet("u01", NotificationPreferences{
+		Conversations: []ConversationPreference{{NotificationTarget: target}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{"/conversation/notification/get.json"}) || prefs.QuietHours != nil {
+		t.Errorf("paths = %v, quietHours = %+v", paths, prefs.QuietHours)
+	}
+	if len(prefs.Conversations) != 1 || prefs.Conversations[0].UnPushLevel == nil || *prefs.Conversations[0].UnPushLevel != 1 {
+		t.Errorf("unexpected conversations: %+v", prefs.Conversations)
+	}
+
+	paths = nil
+	if _, err := rc.NotificationPreferencesGet("u01", NotificationPreferences{QuietHours: &NotificationQuietHours{}}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, []string{"/user/blockPushPeriod/get.json"}) {
+		t.Errorf("paths = %v", paths)
+	}
+}
+
+func TestRongCloud_NotificationPreferencesApply(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	changes, err := rc.NotificationPreferencesApply(NotificationPreferences{
+		UserId:     "u01",
+		QuietHours: &NotificationQuietHours{StartTime: "23:00:00", Period: 480},
+		TypeLevels: map[ConversationType]int{ConversationTypeGroup: ConversationUnPushLevelAtMessage},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(changes)
+}
+
+func TestResolveNotification(t *testing.T) {
+	ug := NotificationTarget{Type: ConversationTypeUG, TargetId: "ug01", BusChannel: "c01"}
+	notSet, atUser := ConversationUnPushLevelNotSet, ConversationUnPushLevelAtUser
+	prefs := NotificationPreferences{
+		UserId:     "u01",
+		QuietHours: &NotificationQuietHours{StartTime: "23:00:00", Period: 480, Level: ConversationUnPushLevelNotRecv},
+		TypeLevels: map[ConversationType]int{ConversationTypeUG: ConversationUnPushLevelNotSet},
+		Conversations: []ConversationPreference{
+			{NotificationTarget: NotificationTarget{Type: ConversationTypeUG, TargetId: "ug01"}, UnPushLevel: &notSet},
+			{NotificationTarget: ug, UnPushLevel: &notSet},
+		},
+		UltraGroupDefaults: []UltraGroupDefaultPreference{
+			{GroupId: "ug01", UnPushLevel: UGUnPushLevelAtMessage},
+			{GroupId: "ug01", BusChannel: "c01", UnPushLevel: UGUnPushLevelNotSet},
+		},
+	}
+	noon := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	d := ResolveNotification(prefs, ug, MentionAll, night)
+	if d.Push || d.Rule != NotificationRuleQuietHours {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+
+	d = ResolveNotification(prefs, ug, MentionNone, noon)
+	if d.Push || d.Rule != NotificationRuleGroupDefault {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+	d = ResolveNotification(prefs, ug, MentionUser, noon)
+	if !d.Push || d.Rule != NotificationRuleGroupDefault {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+
+	prefs.Conversations[1].UnPushLevel = &atUser
+	d = ResolveNotification(prefs, ug, MentionAll, noon)
+	if d.Push || d.Rule != NotificationRuleChannel {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+
+	d = ResolveNotification(NotificationPreferences{}, NotificationTarget{Type: ConversationTypePrivate, TargetId: "u02"}, MentionNone, noon)
+	if !d.Push || d.Rule != NotificationRuleNone {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+}
+
+func TestNotificationResolver_Resolve(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	resolver := rc.NewNotificationResolver(WithResolverTTL(time.Minute))
+	d, err := resolver.Resolve("u01", NotificationTarget{Type: ConversationTypeGroup, TargetId: "g01"}, MentionNone)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(d)
+}
+
+func TestNotificationResolver_InvalidateUltraGroup(t *testing.T) {
+	var defaults int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ultragroup/notdisturb/get.json" {
+			defaults++
+			_, _ = w.Write([]byte(`{"code": 200, "groupId": "ug01", "unpushLevel": 1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "isMuted": 0}`))
+	}))
+	defer server.Close()
+
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	resolver := rc.NewNotificationResolver(WithResolverTTL(time.Minute))
+	target := NotificationTarget{Type: ConversationTypeUG, TargetId: "ug01", BusChannel: "c01"}
+	resolve := func() {
+		if _, err := resolver.Resolve("u01", target, MentionNone); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolve()
+	resolver.Invalidate("u01")
+	resolve()
+	if defaults != 2 {
+		t.Errorf("Invalidate dropped the ultra group defaults: %d queries", defaults)
+	}
+	resolver.InvalidateUltraGroup("ug01")
+	resolve()
+	if defaults != 4 {
+		t.Errorf("InvalidateUltraGroup kept the ultra group defaults: %d queries", defaults)
+	}
+}
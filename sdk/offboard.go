@@ -0,0 +1,261 @@
+// Account offboarding workflow that cleans up a user's data and deactivates the account
+
+package sdk
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	// OffboardStepExpireTokens Invalidates the user's tokens with UserTokenExpire
+	OffboardStepExpireTokens = "expireTokens"
+	// OffboardStepKickGroups Removes the user from all groups with EntrustGroupKickOutAllGroups
+	OffboardStepKickGroups = "kickGroups"
+	// OffboardStepCleanFriends Removes all friends with FriendClean, after a snapshot of the friend list
+	OffboardStepCleanFriends = "cleanFriends"
+	// OffboardStepCleanProfile Removes the hosted profile with UserProfileClean, after a snapshot of the profile
+	OffboardStepCleanProfile = "cleanProfile"
+	// OffboardStepCleanHistory Cleans the history of OffboardRequest.Conversations with ConversationMessageHistoryClean
+	OffboardStepCleanHistory = "cleanHistory"
+	// OffboardStepDeactivate Deactivates the user with UserDeactivate
+	OffboardStepDeactivate = "deactivate"
+	// OffboardStepDelete Deletes the user with UserDelUsers. Not part of the default steps and cannot be reversed
+	OffboardStepDelete = "delete"
+
+	// OffboardStatusDone The step succeeded, in this run or an earlier one
+	OffboardStatusDone = "done"
+	// OffboardStatusFailed The step returned an error
+	OffboardStatusFailed = "failed"
+	// OffboardStatusPending The step was not run because an earlier step failed
+	OffboardStatusPending = "pending"
+)
+
+// OffboardDefaultSteps Steps run when OffboardRequest.Steps is empty, in order
+var OffboardDefaultSteps = []string{
+	OffboardStepExpireTokens,
+	OffboardStepKickGroups,
+	OffboardStepCleanFriends,
+	OffboardStepCleanProfile,
+	OffboardStepCleanHistory,
+	OffboardStepDeactivate,
+}
+
+// OffboardConversation A conversation whose history is cleaned by OffboardStepCleanHistory
+type OffboardConversation struct {
+	ConversationType ConversationType `json:"conversationType"` // ConversationTypePrivate, ConversationTypeGroup, CHATROOM or ConversationTypeSystem
+	TargetId         string           `json:"targetId"`         // Target user, group or chatroom ID
+}
+
+// OffboardRequest User and steps of an offboarding run
+type OffboardRequest struct {
+	UserId        string                 // User ID
+	Steps         []string               // OffboardStep* constants in the order to run them, empty runs OffboardDefaultSteps
+	Conversations []OffboardConversation // Conversations cleaned by OffboardStepCleanHistory
+	Before        int64                  // Tokens issued and messages sent before this millisecond timestamp are affected, 0 uses the current time
+
+	// Previous resumes an earlier run of the same user: steps already done are skipped and the snapshots are kept.
+	Previous *OffboardResult
+}
+
+// OffboardStepResult Outcome of one step
+type OffboardStepResult struct {
+	Step      string `json:"step"`
+	Status    string `json:"status"`              // One of the OffboardStatus* constants
+	Error     string `json:"error,omitempty"`     // Error of a failed step
+	OperateId string `json:"operateId,omitempty"` // Operation ID of OffboardStepDeactivate, reported again in the deactivation callback
+}
+
+// OffboardResult Outcome of an offboarding run, JSON encodable so it can be persisted and passed back to resume
+type OffboardResult struct {
+	UserId  string                 `json:"userId"`
+	Steps   []OffboardStepResult   `json:"steps"`
+	Friends map[string]FriendEntry `json:"friends,omitempty"` // Friend list before OffboardStepCleanFriends
+	Profile *UserProfileResponse   `json:"profile,omitempty"` // Profile before OffboardStepCleanProfile
+}
+
+// Done Reports whether every step succeeded.
+func (r OffboardResult) Done() bool {
+	for _, step := range r.Steps {
+		if step.Status != OffboardStatusDone {
+			return false
+		}
+	}
+	return true
+}
+
+// Offboard Runs the offboarding steps of a user in order and stops at the first failing step.
+// Passing the returned result back in OffboardRequest.Previous retries from the failed step, so a run can be
+// repeated until Done reports true. Every step is safe to repeat on the server as well.
+/*
+*@param  req: User and steps.
+*
+*@return OffboardResult: Outcome of every step and the snapshots taken, also when an error stops the run.
+*@return error: Error of the failing step.
+ */
+func (rc *RongCloud) Offboard(req OffboardRequest) (OffboardResult, error) {
+	result := OffboardResult{UserId: req.UserId}
+	if req.UserId == "" {
+		return result, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	done := map[string]bool{}
+	if req.Previous != nil {
+		if req.Previous.UserId != req.UserId {
+			return result, RCErrorNew(1002, "Previous result belongs to user '"+req.Previous.UserId+"'")
+		}
+		result.Friends, result.Profile = req.Previous.Friends, req.Previous.Profile
+		for _, step := range req.Previous.Steps {
+			if step.Status == OffboardStatusDone {
+				done[step.Step] = true
+			}
+		}
+	}
+	steps := req.Steps
+	if len(steps) == 0 {
+		steps = OffboardDefaultSteps
+	}
+	for _, step := range steps {
+		if !validOffboardStep(step) {
+			return result, RCErrorNew(1002, "Unknown offboarding step '"+step+"'")
+		}
+	}
+	if req.Before <= 0 {
+		req.Before = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+
+	var failure error
+	for _, step := range steps {
+		stepResult := OffboardStepResult{Step: step, Status: OffboardStatusDone}
+		switch {
+		case failure != nil:
+			stepResult.Status = OffboardStatusPending
+		case done[step]:
+			if req.Previous != nil {
+				for _, previous := range req.Previous.Steps {
+					if previous.Step == step {
+						stepResult.OperateId = previous.OperateId
+					}
+				}
+			}
+		default:
+			operateId, err := rc.offboardStep(req, step, &result)
+			stepResult.OperateId = operateId
+			if err != nil {
+				failure = err
+				stepResult.Status, stepResult.Error = OffboardStatusFailed, err.Error()
+			}
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+	return result, failure
+}
+
+func (rc *RongCloud) offboardStep(req OffboardRequest, step string, result *OffboardResult) (string, error) {
+	userId := req.UserId
+	switch step {
+	case OffboardStepExpireTokens:
+		_, err := rc.UserTokenExpire(userId, req.Before)
+		return "", err
+	case OffboardStepKickGroups:
+		_, err := rc.EntrustGroupKickOutAllGroups(userId)
+		return "", err
+	case OffboardStepCleanFriends:
+		if result.Friends == nil {
+			graph, err := rc.FriendGraphGet(userId)
+			if err != nil {
+				return "", err
+			}
+			result.Friends = graph[userId]
+		}
+		_, err := rc.FriendClean(userId)
+		return "", err
+	case OffboardStepCleanProfile:
+		if result.Profile == nil {
+			res, err := rc.UserProfilBatchQuery(userId)
+			if err != nil {
+				return "", err
+			}
+			profile := UserProfileResponse{UserId: userId}
+			for _, p := range res.UserProfiles {
+				if p.UserId == userId {
+					profile = p
+				}
+			}
+			result.Profile = &profile
+		}
+		return "", rc.UserProfileClean(userId)
+	case OffboardStepCleanHistory:
+		for _, c := range req.Conversations {
+			err := rc.ConversationMessageHistoryClean(strconv.Itoa(int(c.ConversationType)), userId, c.TargetId,
+				WithCleanMsgTimestamp(req.Before))
+			if err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	case OffboardStepDeactivate:
+		res, err := rc.UserDeactivate([]string{userId})
+		if err != nil {
+			return "", err
+		}
+		return res.OperateId, nil
+	case OffboardStepDelete:
+		return "", rc.UserDelUsers([]string{userId})
+	}
+	return "", RCErrorNew(1002, "Unknown offboarding step '"+step+"'")
+}
+
+// OffboardReverse Reverses an offboarding run as far as possible: the user is reactivated with UserReactivate,
+// the profile snapshot is written back and the friends of the snapshot are added again with FriendGraphApply.
+// Group memberships, expired tokens, cleaned history and deleted users cannot be restored.
+/*
+*@param  result: Result of the offboarding run.
+*
+*@return string: Operation ID of the reactivation, empty when the user was not deactivated.
+*@return error
+ */
+func (rc *RongCloud) OffboardReverse(result OffboardResult) (string, error) {
+	if result.UserId == "" {
+		return "", RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	steps := map[string]bool{}
+	for _, step := range result.Steps {
+		if step.Status == OffboardStatusDone {
+			steps[step.Step] = true
+		}
+	}
+	if steps[OffboardStepDelete] {
+		return "", RCErrorNew(1002, "User '"+result.UserId+"' was deleted and cannot be restored")
+	}
+
+	operateId := ""
+	if steps[OffboardStepDeactivate] {
+		res, err := rc.UserReactivate([]string{result.UserId})
+		if err != nil {
+			return "", err
+		}
+		operateId = res.OperateId
+	}
+	if steps[OffboardStepCleanProfile] && result.Profile != nil &&
+		(result.Profile.UserProfile != "" || result.Profile.UserExtProfile != "") {
+		if err := rc.UserProfileSet(result.UserId, result.Profile.UserProfile, result.Profile.UserExtProfile); err != nil {
+			return operateId, err
+		}
+	}
+	if steps[OffboardStepCleanFriends] && len(result.Friends) > 0 {
+		diff := FriendGraph{result.UserId: result.Friends}.Diff(FriendGraph{})
+		if _, err := rc.FriendGraphApply(diff); err != nil {
+			return operateId, err
+		}
+	}
+	return operateId, nil
+}
+
+func validOffboardStep(step string) bool {
+	switch step {
+	case OffboardStepExpireTokens, OffboardStepKickGroups, OffboardStepCleanFriends, OffboardStepCleanProfile,
+		OffboardStepCleanHistory, OffboardStepDeactivate, OffboardStepDelete:
+		return true
+	}
+	return false
+}
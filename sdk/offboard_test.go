@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOffboard_Resume(t *testing.T) {
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	previous := OffboardResult{UserId: "u01", Friends: map[string]FriendEntry{"u02": {RemarkName: "Bob"}}}
+	for _, step := range OffboardDefaultSteps {
+		previous.Steps = append(previous.Steps, OffboardStepResult{Step: step, Status: OffboardStatusDone})
+	}
+	previous.Steps[len(previous.Steps)-1].OperateId = "op-1"
+
+	// Every step is already done, so nothing is sent.
+	result, err := rc.Offboard(OffboardRequest{UserId: "u01", Previous: &previous})
+	if err != nil || !result.Done() {
+		t.Fatalf("unexpected result: %+v %v", result, err)
+	}
+	if result.Steps[len(result.Steps)-1].OperateId != "op-1" || result.Friends["u02"].RemarkName != "Bob" {
+		t.Errorf("previous run not carried over: %+v", result)
+	}
+
+	if _, err := rc.Offboard(OffboardRequest{UserId: "u02", Previous: &previous}); err == nil {
+		t.Error("expected error for a result of another user")
+	}
+	if _, err := rc.Offboard(OffboardRequest{UserId: "u01", Steps: []string{"archive"}}); err == nil {
+		t.Error("expected error for unknown step")
+	}
+	if _, err := rc.OffboardReverse(OffboardResult{UserId: "u01", Steps: []OffboardStepResult{
+		{Step: OffboardStepDelete, Status: OffboardStatusDone}}}); err == nil {
+		t.Error("expected error for a deleted user")
+	}
+}
+
+func TestRongCloud_Offboard(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	result, err := rc.Offboard(OffboardRequest{
+		UserId:        "u01",
+		Conversations: []OffboardConversation{{ConversationType: ConversationTypePrivate, TargetId: "u02"}},
+	})
+	t.Logf("offboard: %+v", result)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	operateId, err := rc.OffboardReverse(result)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Logf("reactivate operateId: %s", operateId)
+}
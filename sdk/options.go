@@ -40,3 +40,26 @@ func WithTransport(transport http.RoundTripper) rongCloudOption {
 		o.globalTransport = transport
 	}
 }
+
+// WithCredentialProvider signs requests with the App Secret supplied by provider instead of the fixed appSecret
+func WithCredentialProvider(provider CredentialProvider) rongCloudOption {
+	return func(o *RongCloud) {
+		o.credentialProvider = provider
+	}
+}
+
+// WithIdempotencyCache sets where the results of sends with WithIdempotencyKey are kept and for how long.
+// The default is a MemoryIdempotencyCache with IDEMPOTENCY_TTL, nil disables deduplication
+func WithIdempotencyCache(cache IdempotencyCache, ttl time.Duration) rongCloudOption {
+	return func(o *RongCloud) {
+		o.idempotencyCache, o.idempotencyTTL = cache, ttl
+	}
+}
+
+// WithCircuitBreaker fails requests at once while their domain and endpoint family keep failing, see NewCircuitBreaker.
+// While the circuit of the current domain is open, requests switch to the backup domain
+func WithCircuitBreaker(breaker *CircuitBreaker) rongCloudOption {
+	return func(o *RongCloud) {
+		o.circuitBreaker = breaker
+	}
+}
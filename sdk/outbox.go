@@ -0,0 +1,578 @@
+// Outbox that persists sends before delivering them, so that they survive a crash of the process
+
+package sdk
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OUTBOX_MAX_ATTEMPTS Default number of failed attempts after which an entry is dead-lettered
+	OUTBOX_MAX_ATTEMPTS = 10
+	// OUTBOX_BACKOFF Default wait after the first failed attempt, doubled after each following failure
+	OUTBOX_BACKOFF = time.Second
+	// OUTBOX_MAX_BACKOFF Default maximum wait between two attempts
+	OUTBOX_MAX_BACKOFF = 5 * time.Minute
+)
+
+// OutboxStatus State of an OutboxEntry
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"   // Waiting for delivery
+	OutboxStatusDelivered OutboxStatus = "delivered" // Sent, kept so that the key keeps deduplicating until Purge
+	OutboxStatusDead      OutboxStatus = "dead"      // Failed OUTBOX_MAX_ATTEMPTS times, see Requeue
+)
+
+// OutboxMessage A message send that can be stored. Content is the JSON of the message, as returned by ToString.
+type OutboxMessage struct {
+	ConversationType     ConversationType `json:"conversationType"` // ConversationTypePrivate (PrivateSend), ConversationTypeGroup (GroupSend) or ConversationTypeSystem (SystemSend)
+	SenderId             string           `json:"senderId"`
+	TargetIds            []string         `json:"targetIds"`
+	UserIds              []string         `json:"userIds,omitempty"` // Group members a group message is directed to
+	ObjectName           string           `json:"objectName"`
+	Content              string           `json:"content"`
+	PushContent          string           `json:"pushContent,omitempty"`
+	PushData             string           `json:"pushData,omitempty"`
+	PushExt              string           `json:"pushExt,omitempty"`
+	DisablePush          bool             `json:"disablePush,omitempty"`
+	Count                int              `json:"count,omitempty"`
+	VerifyBlacklist      int              `json:"verifyBlacklist,omitempty"`
+	IsPersisted          int              `json:"isPersisted"`
+	IsIncludeSender      int              `json:"isIncludeSender,omitempty"`
+	IsMentioned          int              `json:"isMentioned,omitempty"`
+	ContentAvailable     int              `json:"contentAvailable,omitempty"`
+	Expansion            bool             `json:"expansion,omitempty"`
+	ExtraContent         string           `json:"extraContent,omitempty"`
+	BusChannel           string           `json:"busChannel,omitempty"`
+	DisableUpdateLastMsg bool             `json:"disableUpdateLastMsg,omitempty"`
+}
+
+// NewOutboxMessage creates an OutboxMessage with IsPersisted set to 1, the default of the send APIs
+/*
+*@param  conversationType: ConversationTypePrivate, ConversationTypeGroup or ConversationTypeSystem.
+*@param  senderId: Sender user ID.
+*@param  targetIds: Recipient user IDs, or group IDs.
+*@param  objectName: Message type, such as "RC:TxtMsg".
+*@param  msg: Message content.
+*
+*@return OutboxMessage, error
+ */
+func NewOutboxMessage(conversationType ConversationType, senderId string, targetIds []string, objectName string, msg rcMsg) (OutboxMessage, error) {
+	content, err := msg.ToString()
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	return OutboxMessage{
+		ConversationType: conversationType,
+		SenderId:         senderId,
+		TargetIds:        targetIds,
+		ObjectName:       objectName,
+		Content:          content,
+		IsPersisted:      1,
+	}, nil
+}
+
+// outboxContent sends stored content unchanged
+type outboxContent string
+
+func (c outboxContent) ToString() (string, error) {
+	return string(c), nil
+}
+
+func (m OutboxMessage) validate() error {
+	switch m.ConversationType {
+	case ConversationTypePrivate, ConversationTypeGroup, ConversationTypeSystem:
+	default:
+		return RCErrorNew(1002, "Paramer 'conversationType' was wrong")
+	}
+	if m.SenderId == "" {
+		return RCErrorNew(1002, "Paramer 'senderId' is required")
+	}
+	if len(m.TargetIds) == 0 {
+		return RCErrorNew(1002, "Paramer 'targetIds' is required")
+	}
+	if m.ObjectName == "" {
+		return RCErrorNew(1002, "Paramer 'objectName' is required")
+	}
+	if m.Content == "" {
+		return RCErrorNew(1002, "Paramer 'content' is required")
+	}
+	return nil
+}
+
+func (m OutboxMessage) send(rc *RongCloud) (MessageResult, error) {
+	options := []MsgOption{
+		WithMsgDisablePush(m.DisablePush),
+		WithMsgPushExt(m.PushExt),
+		WithMsgBusChannel(m.BusChannel),
+		WithMsgExpansion(m.Expansion),
+		WithExtraContent(m.ExtraContent),
+		WithDisableUpdateLastMsg(m.DisableUpdateLastMsg),
+	}
+	content := outboxContent(m.Content)
+	switch m.ConversationType {
+	case ConversationTypeGroup:
+		options = append(options, WithMsgMentioned(m.IsMentioned), WithMsgContentAvailable(m.ContentAvailable))
+		return rc.GroupSend(m.SenderId, m.TargetIds, m.UserIds, m.ObjectName, content, m.PushContent, m.PushData,
+			m.IsPersisted, m.IsIncludeSender, options...)
+	case ConversationTypeSystem:
+		options = append(options, WithMsgContentAvailable(m.ContentAvailable))
+		return rc.SystemSend(m.SenderId, m.TargetIds, m.ObjectName, content, m.PushContent, m.PushData,
+			m.Count, m.IsPersisted, options...)
+	}
+	return rc.PrivateSend(m.SenderId, m.TargetIds, m.ObjectName, content, m.PushContent, m.PushData,
+		m.Count, m.VerifyBlacklist, m.IsPersisted, m.IsIncludeSender, m.ContentAvailable, options...)
+}
+
+// OutboxEntry A stored send
+type OutboxEntry struct {
+	Key         string         `json:"key"` // Caller's deduplication key
+	Message     OutboxMessage  `json:"message"`
+	Status      OutboxStatus   `json:"status"`
+	Attempts    int            `json:"attempts"`
+	LastError   string         `json:"lastError,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	NextAttempt time.Time      `json:"nextAttempt"`
+	DeliveredAt time.Time      `json:"deliveredAt,omitempty"`
+	Result      *MessageResult `json:"result,omitempty"` // Set once delivered
+}
+
+// OutboxStore Storage of OutboxEntry keyed by the caller's key. Implementations must be safe for concurrent use.
+type OutboxStore interface {
+	// Add saves a new entry. It returns false and leaves the store unchanged when an entry with the same key exists
+	Add(entry OutboxEntry) (bool, error)
+	// Put saves the entry, replacing the entry with the same key
+	Put(entry OutboxEntry) error
+	// Get returns the entry, and false when there is none
+	Get(key string) (OutboxEntry, bool, error)
+	// List returns the entries with the status, oldest first
+	List(status OutboxStatus) ([]OutboxEntry, error)
+	// Delete removes the entry. Deleting a missing entry is not an error
+	Delete(key string) error
+}
+
+// sortOutboxEntries orders entries by creation time, then by key
+func sortOutboxEntries(entries []OutboxEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+		}
+		return entries[i].Key < entries[j].Key
+	})
+}
+
+// MemoryOutboxStore OutboxStore kept in process memory. Entries are lost with the process, so it only suits tests
+// and processes that can afford to lose sends.
+type MemoryOutboxStore struct {
+	lock    sync.RWMutex
+	entries map[string]OutboxEntry
+}
+
+// NewMemoryOutboxStore creates an empty MemoryOutboxStore
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{entries: map[string]OutboxEntry{}}
+}
+
+// Add implements OutboxStore
+func (s *MemoryOutboxStore) Add(entry OutboxEntry) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.entries[entry.Key]; ok {
+		return false, nil
+	}
+	s.entries[entry.Key] = entry
+	return true, nil
+}
+
+// Put implements OutboxStore
+func (s *MemoryOutboxStore) Put(entry OutboxEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entries[entry.Key] = entry
+	return nil
+}
+
+// Get implements OutboxStore
+func (s *MemoryOutboxStore) Get(key string) (OutboxEntry, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+// List implements OutboxStore
+func (s *MemoryOutboxStore) List(status OutboxStatus) ([]OutboxEntry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var entries []OutboxEntry
+	for _, entry := range s.entries {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	sortOutboxEntries(entries)
+	return entries, nil
+}
+
+// Delete implements OutboxStore
+func (s *MemoryOutboxStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// FileOutboxStore OutboxStore keeping one JSON file per entry in a directory. Files are written to a temporary
+// file, synced and then moved into place, so an entry is either stored completely or not at all.
+// Several processes may share the directory: Add creates the file exclusively.
+type FileOutboxStore struct {
+	dir  string
+	lock sync.Mutex
+}
+
+// NewFileOutboxStore creates a FileOutboxStore, creating the directory when missing
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileOutboxStore{dir: dir}, nil
+}
+
+// path names the file of a key after its SHA1, so that any key is a valid file name
+func (s *FileOutboxStore) path(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// writeTemp writes the entry to a synced temporary file and returns its name
+func (s *FileOutboxStore) writeTemp(entry OutboxEntry) (string, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Add implements OutboxStore
+func (s *FileOutboxStore) Add(entry OutboxEntry) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tmp, err := s.writeTemp(entry)
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp)
+	// Link fails when the file exists, unlike Rename.
+	if err := os.Link(tmp, s.path(entry.Key)); err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Put implements OutboxStore
+func (s *FileOutboxStore) Put(entry OutboxEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tmp, err := s.writeTemp(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path(entry.Key)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Get implements OutboxStore
+func (s *FileOutboxStore) Get(key string) (OutboxEntry, bool, error) {
+	entry, err := s.read(s.path(key))
+	if os.IsNotExist(err) {
+		return OutboxEntry{}, false, nil
+	}
+	return entry, err == nil, err
+}
+
+func (s *FileOutboxStore) read(path string) (OutboxEntry, error) {
+	var entry OutboxEntry
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, RCErrorNew(1002, path+": "+err.Error())
+	}
+	return entry, nil
+}
+
+// List implements OutboxStore
+func (s *FileOutboxStore) List(status OutboxStatus) ([]OutboxEntry, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []OutboxEntry
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		entry, err := s.read(filepath.Join(s.dir, file.Name()))
+		if os.IsNotExist(err) {
+			// Deleted since ReadDir
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	sortOutboxEntries(entries)
+	return entries, nil
+}
+
+// Delete implements OutboxStore
+func (s *FileOutboxStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Outbox Persists sends first and delivers them later with a relay, so that a send accepted by Add is not lost when
+// the process stops. The relay retries failed sends with exponential backoff and dead-letters them after a number of
+// failures. Delivery is at least once: a process stopping between a successful send and storing its result sends the
+// message again on the next run.
+type Outbox struct {
+	rc          *RongCloud
+	store       OutboxStore
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	onDead      func(OutboxEntry)
+	now         func() time.Time
+	relayLock   sync.Mutex
+}
+
+// OutboxOption Functional option of NewOutbox
+type OutboxOption func(*Outbox)
+
+// WithOutboxMaxAttempts sets the number of failed attempts after which an entry is dead-lettered, default OUTBOX_MAX_ATTEMPTS
+func WithOutboxMaxAttempts(n int) OutboxOption {
+	return func(o *Outbox) {
+		o.maxAttempts = n
+	}
+}
+
+// WithOutboxBackoff sets the wait after the first failure and the maximum wait, default OUTBOX_BACKOFF and OUTBOX_MAX_BACKOFF
+func WithOutboxBackoff(backoff, maxBackoff time.Duration) OutboxOption {
+	return func(o *Outbox) {
+		o.backoff, o.maxBackoff = backoff, maxBackoff
+	}
+}
+
+// WithOutboxDeadLetter sets a function called with every entry that is dead-lettered
+func WithOutboxDeadLetter(onDead func(OutboxEntry)) OutboxOption {
+	return func(o *Outbox) {
+		o.onDead = onDead
+	}
+}
+
+// NewOutbox creates an Outbox. A nil store uses a new MemoryOutboxStore.
+func (rc *RongCloud) NewOutbox(store OutboxStore, options ...OutboxOption) *Outbox {
+	if store == nil {
+		store = NewMemoryOutboxStore()
+	}
+	o := &Outbox{
+		rc:          rc,
+		store:       store,
+		maxAttempts: OUTBOX_MAX_ATTEMPTS,
+		backoff:     OUTBOX_BACKOFF,
+		maxBackoff:  OUTBOX_MAX_BACKOFF,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if o.maxAttempts < 1 {
+		o.maxAttempts = 1
+	}
+	return o
+}
+
+// Add Stores a send for delivery. Commit it together with, or right after, the business data it belongs to.
+/*
+*@param  key: Caller's deduplication key, such as a business message ID. A key already in the outbox is not added again.
+*@param  msg: The send.
+*
+*@return OutboxEntry: The stored entry, or the existing entry when the key was added before.
+*@return bool: false when the key was added before.
+*@return error
+ */
+func (o *Outbox) Add(key string, msg OutboxMessage) (OutboxEntry, bool, error) {
+	if key == "" {
+		return OutboxEntry{}, false, RCErrorNew(1002, "Paramer 'key' is required")
+	}
+	if err := msg.validate(); err != nil {
+		return OutboxEntry{}, false, err
+	}
+	now := o.now()
+	entry := OutboxEntry{Key: key, Message: msg, Status: OutboxStatusPending, CreatedAt: now, NextAttempt: now}
+	added, err := o.store.Add(entry)
+	if err != nil || added {
+		return entry, added, err
+	}
+	existing, _, err := o.store.Get(key)
+	return existing, false, err
+}
+
+// Get Returns the entry of a key, and false when there is none.
+func (o *Outbox) Get(key string) (OutboxEntry, bool, error) {
+	return o.store.Get(key)
+}
+
+// Relay Sends every pending entry that is due, oldest first, and stores the outcome.
+// Concurrent calls in one process are serialized.
+/*
+*@return int: Number of entries delivered.
+*@return error: Error of the store. Send errors are recorded in the entries.
+ */
+func (o *Outbox) Relay() (int, error) {
+	o.relayLock.Lock()
+	defer o.relayLock.Unlock()
+	entries, err := o.store.List(OutboxStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, entry := range entries {
+		if entry.NextAttempt.After(o.now()) {
+			continue
+		}
+		result, err := entry.Message.send(o.rc)
+		entry.Attempts++
+		if err == nil {
+			entry.Status, entry.LastError, entry.Result, entry.DeliveredAt = OutboxStatusDelivered, "", &result, o.now()
+			delivered++
+		} else {
+			entry.LastError = err.Error()
+			if entry.Attempts >= o.maxAttempts {
+				entry.Status = OutboxStatusDead
+			} else {
+				entry.NextAttempt = o.now().Add(o.delay(entry.Attempts))
+			}
+		}
+		if err := o.store.Put(entry); err != nil {
+			return delivered, err
+		}
+		if entry.Status == OutboxStatusDead && o.onDead != nil {
+			o.onDead(entry)
+		}
+	}
+	return delivered, nil
+}
+
+// delay returns the wait after the given number of failed attempts
+func (o *Outbox) delay(attempts int) time.Duration {
+	delay := o.backoff
+	for i := 1; i < attempts && delay < o.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > o.maxBackoff {
+		delay = o.maxBackoff
+	}
+	return delay
+}
+
+// Run Calls Relay every interval until stop is closed.
+/*
+*@param  interval: Time between two relays.
+*@param  stop: Closed to end Run.
+*@param  onError: Called with store errors, may be nil.
+ */
+func (o *Outbox) Run(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := o.Relay(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Dead Returns the dead-lettered entries, oldest first.
+func (o *Outbox) Dead() ([]OutboxEntry, error) {
+	return o.store.List(OutboxStatusDead)
+}
+
+// Requeue Moves a dead-lettered entry back to pending with its attempts reset, to be sent by the next Relay.
+func (o *Outbox) Requeue(key string) error {
+	entry, ok, err := o.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok || entry.Status != OutboxStatusDead {
+		return RCErrorNew(1002, "Paramer 'key' names no dead entry")
+	}
+	entry.Status, entry.Attempts, entry.NextAttempt = OutboxStatusPending, 0, o.now()
+	return o.store.Put(entry)
+}
+
+// Purge Deletes the entries delivered more than olderThan ago. Their keys no longer deduplicate afterwards.
+/*
+*@return int: Number of entries deleted.
+*@return error
+ */
+func (o *Outbox) Purge(olderThan time.Duration) (int, error) {
+	entries, err := o.store.List(OutboxStatusDelivered)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := o.now().Add(-olderThan)
+	purged := 0
+	for _, entry := range entries {
+		if entry.DeliveredAt.After(cutoff) {
+			continue
+		}
+		if err := o.store.Delete(entry.Key); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
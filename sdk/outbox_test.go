@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// outboxServer answers /message/*/publish.json, failing with a 500 while fail returns true
+func outboxServer(fail func(r *http.Request) bool) (*httptest.Server, *[]string) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		paths = append(paths, r.URL.Path+"?"+r.Form.Get("content"))
+		if fail(r) {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code": 1000, "errorMessage": "Internal error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "messageUID": "uid-` + r.Form.Get("fromUserId") + `"}`))
+	}))
+	return server, &paths
+}
+
+func testOutboxStore(t *testing.T, store OutboxStore) {
+	failing := true
+	server, paths := outboxServer(func(r *http.Request) bool { return failing && r.URL.Path == "/message/group/publish.json" })
+	defer server.Close()
+
+	now := time.Unix(1700000000, 0)
+	var dead []OutboxEntry
+	client := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	outbox := client.NewOutbox(store, WithOutboxMaxAttempts(3), WithOutboxBackoff(time.Second, 3*time.Second),
+		WithOutboxDeadLetter(func(entry OutboxEntry) { dead = append(dead, entry) }))
+	outbox.now = func() time.Time { return now }
+
+	private, err := NewOutboxMessage(ConversationTypePrivate, "u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := private
+	group.ConversationType, group.SenderId, group.TargetIds = ConversationTypeGroup, "u03", []string{"g01"}
+
+	if _, added, err := outbox.Add("order-1", private); err != nil || !added {
+		t.Fatalf("add = %v, %v", added, err)
+	}
+	now = now.Add(time.Millisecond)
+	if _, added, err := outbox.Add("order-2", group); err != nil || !added {
+		t.Fatalf("add = %v, %v", added, err)
+	}
+	if existing, added, err := outbox.Add("order-1", group); err != nil || added || existing.Message.SenderId != "u01" {
+		t.Errorf("duplicate add = %+v, %v, %v", existing, added, err)
+	}
+	if _, _, err := outbox.Add("order-3", OutboxMessage{}); err == nil {
+		t.Error("invalid message accepted")
+	}
+
+	if delivered, err := outbox.Relay(); err != nil || delivered != 1 {
+		t.Fatalf("relay = %d, %v", delivered, err)
+	}
+	entry, _, _ := outbox.Get("order-1")
+	if entry.Status != OutboxStatusDelivered || entry.Result == nil || entry.Result.MessageUID != "uid-u01" {
+		t.Errorf("order-1 = %+v", entry)
+	}
+	entry, _, _ = outbox.Get("order-2")
+	if entry.Status != OutboxStatusPending || entry.Attempts != 1 || !entry.NextAttempt.Equal(now.Add(time.Second)) {
+		t.Errorf("order-2 = %+v", entry)
+	}
+
+	// Not due yet, then failing until dead-lettered after 3 attempts.
+	if _, err := outbox.Relay(); err != nil || len(*paths) != 2 {
+		t.Fatalf("early relay sent: %v, %v", *paths, err)
+	}
+	for i := 0; i < 2; i++ {
+		now = now.Add(3 * time.Second)
+		if _, err := outbox.Relay(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(dead) != 1 || dead[0].Key != "order-2" || dead[0].Attempts != 3 {
+		t.Fatalf("dead = %+v", dead)
+	}
+	if list, _ := outbox.Dead(); len(list) != 1 {
+		t.Errorf("dead list = %+v", list)
+	}
+
+	failing = false
+	if err := outbox.Requeue("order-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Requeue("order-1"); err == nil {
+		t.Error("delivered entry requeued")
+	}
+	if delivered, err := outbox.Relay(); err != nil || delivered != 1 {
+		t.Fatalf("relay after requeue = %d, %v", delivered, err)
+	}
+	if (*paths)[len(*paths)-1] != `/message/group/publish.json?{"content":"hello","user":{"id":"","name":"","icon":"","portrait":"","extra":""},"extra":""}` {
+		t.Errorf("last request = %s", (*paths)[len(*paths)-1])
+	}
+
+	now = now.Add(time.Hour)
+	if purged, err := outbox.Purge(time.Minute); err != nil || purged != 2 {
+		t.Errorf("purge = %d, %v", purged, err)
+	}
+	if _, ok, _ := outbox.Get("order-1"); ok {
+		t.Error("purged entry still stored")
+	}
+}
+
+func TestOutbox_Memory(t *testing.T) {
+	testOutboxStore(t, NewMemoryOutboxStore())
+}
+
+func TestOutbox_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileOutboxStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testOutboxStore(t, store)
+
+	// Entries survive a new store on the same directory, as after a restart.
+	msg, _ := NewOutboxMessage(ConversationTypeSystem, "admin", []string{"u01"}, "RC:TxtMsg", &TXTMsg{Content: "hi"})
+	if _, err := store.Add(OutboxEntry{Key: "restart", Message: msg, Status: OutboxStatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewFileOutboxStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := reopened.List(OutboxStatusPending)
+	if err != nil || len(pending) != 1 || pending[0].Message.ConversationType != ConversationTypeSystem {
+		t.Errorf("pending = %+v, %v", pending, err)
+	}
+	if added, err := reopened.Add(OutboxEntry{Key: "restart"}); err != nil || added {
+		t.Errorf("duplicate add = %v, %v", added, err)
+	}
+}
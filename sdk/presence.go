@@ -0,0 +1,270 @@
+// Presence tracker fed by online status callbacks and OnlineStatusCheck polling
+
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// PRESENCE_POLL_CONCURRENCY Number of OnlineStatusCheck requests a refresh runs at the same time
+	PRESENCE_POLL_CONCURRENCY = 10
+
+	// PresenceSourceCallback The change was reported by the online status callback
+	PresenceSourceCallback = "callback"
+	// PresenceSourcePoll The change was found by OnlineStatusCheck
+	PresenceSourcePoll = "poll"
+)
+
+// OnlineStatusCallback One entry of the online status callback body
+type OnlineStatusCallback struct {
+	UserId   string `json:"userid"`   // User ID
+	Status   string `json:"status"`   // 0: online, 1: offline, 2: logged out
+	Os       string `json:"os"`       // Client platform
+	Time     int64  `json:"time"`     // Time of the change in milliseconds
+	ClientIp string `json:"clientIp"` // Client IP and port
+}
+
+// Online Reports whether the entry is an online event.
+func (c OnlineStatusCallback) Online() bool {
+	return c.Status == "0"
+}
+
+// PresenceEvent A change of a user's online status
+type PresenceEvent struct {
+	UserId string // User ID
+	Online bool   // New status
+	Time   int64  // Time of the change in milliseconds
+	Source string // PresenceSourceCallback or PresenceSourcePoll
+}
+
+type presenceEntry struct {
+	known   bool
+	online  bool
+	time    int64     // Time of the last change, as reported
+	checked time.Time // When the entry was last confirmed
+}
+
+// PresenceTracker In-memory online status table of the tracked users, safe for concurrent use.
+// Callbacks keep it current, and Refresh polls the entries that have not been confirmed within the stale period.
+type PresenceTracker struct {
+	rc          *RongCloud
+	staleAfter  time.Duration
+	check       func(userId string) (int, error)
+	lock        sync.RWMutex
+	entries     map[string]*presenceEntry
+	subscribers map[chan PresenceEvent]bool
+}
+
+// NewPresenceTracker creates a PresenceTracker.
+/*
+*@param  staleAfter: Entries not confirmed by a callback or a poll within this period are polled by Refresh.
+*
+*@return *PresenceTracker
+ */
+func (rc *RongCloud) NewPresenceTracker(staleAfter time.Duration) *PresenceTracker {
+	return &PresenceTracker{
+		rc:          rc,
+		staleAfter:  staleAfter,
+		check:       rc.OnlineStatusCheck,
+		entries:     map[string]*presenceEntry{},
+		subscribers: map[chan PresenceEvent]bool{},
+	}
+}
+
+// Track Adds users to the table with an unknown status until the next callback or Refresh.
+func (p *PresenceTracker) Track(userIds ...string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, userId := range userIds {
+		if _, ok := p.entries[userId]; !ok && userId != "" {
+			p.entries[userId] = &presenceEntry{}
+		}
+	}
+}
+
+// Untrack Removes users from the table.
+func (p *PresenceTracker) Untrack(userIds ...string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, userId := range userIds {
+		delete(p.entries, userId)
+	}
+}
+
+// IsOnline Returns the status of a user, and false for known when the user is not tracked or has no status yet.
+func (p *PresenceTracker) IsOnline(userId string) (online bool, known bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	entry, ok := p.entries[userId]
+	if !ok {
+		return false, false
+	}
+	return entry.online, entry.known
+}
+
+// Lookup Returns the status of every user with a known status, keyed by user ID.
+func (p *PresenceTracker) Lookup(userIds ...string) map[string]bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	status := make(map[string]bool, len(userIds))
+	for _, userId := range userIds {
+		if entry, ok := p.entries[userId]; ok && entry.known {
+			status[userId] = entry.online
+		}
+	}
+	return status
+}
+
+// Subscribe Returns a channel receiving every status change, and a function that closes it.
+// Events are dropped for a subscriber whose buffer is full, so the table never waits for a slow reader.
+func (p *PresenceTracker) Subscribe(buffer int) (<-chan PresenceEvent, func()) {
+	ch := make(chan PresenceEvent, buffer)
+	p.lock.Lock()
+	p.subscribers[ch] = true
+	p.lock.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			p.lock.Lock()
+			delete(p.subscribers, ch)
+			p.lock.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Apply Records an online status callback body. Entries of untracked users are ignored, and an entry older
+// than the status already known for the user does not override it.
+func (p *PresenceTracker) Apply(callbacks []OnlineStatusCallback) {
+	for _, c := range callbacks {
+		p.set(PresenceEvent{UserId: c.UserId, Online: c.Online(), Time: c.Time, Source: PresenceSourceCallback})
+	}
+}
+
+// ServeHTTP Handles online status callback requests: the signature is checked, the body is parsed and applied.
+func (p *PresenceTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !p.rc.checkCallbackSignature(query.Get("nonce"), query.Get("signTimestamp"), query.Get("signature")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	callbacks, err := ParseOnlineStatusCallback(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.Apply(callbacks)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Refresh Polls OnlineStatusCheck for every tracked user whose entry is unknown or stale,
+// running up to PRESENCE_POLL_CONCURRENCY requests at the same time.
+/*
+*@return error: The first polling error. The other users are still polled.
+ */
+func (p *PresenceTracker) Refresh() error {
+	now := time.Now()
+	var stale []string
+	p.lock.RLock()
+	for userId, entry := range p.entries {
+		if !entry.known || now.Sub(entry.checked) >= p.staleAfter {
+			stale = append(stale, userId)
+		}
+	}
+	p.lock.RUnlock()
+
+	var (
+		wg       sync.WaitGroup
+		errLock  sync.Mutex
+		firstErr error
+	)
+	queue := make(chan string)
+	for i := 0; i < PRESENCE_POLL_CONCURRENCY && i < len(stale); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userId := range queue {
+				status, err := p.check(userId)
+				if err != nil {
+					errLock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errLock.Unlock()
+					continue
+				}
+				p.set(PresenceEvent{UserId: userId, Online: status == 1,
+					Time: time.Now().UnixNano() / int64(time.Millisecond), Source: PresenceSourcePoll})
+			}
+		}()
+	}
+	for _, userId := range stale {
+		queue <- userId
+	}
+	close(queue)
+	wg.Wait()
+	return firstErr
+}
+
+// Run Calls Refresh every interval until stop is closed. Polling errors are passed to onError when it is not nil.
+func (p *PresenceTracker) Run(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := p.Refresh(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (p *PresenceTracker) set(event PresenceEvent) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	entry, ok := p.entries[event.UserId]
+	if !ok || entry.known && event.Time < entry.time {
+		return
+	}
+	changed := !entry.known || entry.online != event.Online
+	entry.known, entry.online, entry.time, entry.checked = true, event.Online, event.Time, time.Now()
+	if !changed {
+		return
+	}
+	for ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// ParseOnlineStatusCallback Decodes the body of an online status callback request.
+func ParseOnlineStatusCallback(body []byte) ([]OnlineStatusCallback, error) {
+	var callbacks []OnlineStatusCallback
+	if err := json.Unmarshal(body, &callbacks); err != nil {
+		return nil, err
+	}
+	return callbacks, nil
+}
+
+// checkCallbackSignature verifies the signature query parameter of a callback request:
+// the SHA1 of App Secret, nonce and signTimestamp. During a rotation window the previous secret is accepted too.
+func (rc *RongCloud) checkCallbackSignature(nonce, signTimestamp, signature string) bool {
+	creds := rc.credentials()
+	return VerifySignature(creds.AppSecret, nonce, signTimestamp, signature) ||
+		VerifySignature(creds.PreviousAppSecret, nonce, signTimestamp, signature)
+}
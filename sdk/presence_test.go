@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPresenceTracker(t *testing.T) {
+	p := NewRongCloud("key", "secret", REGION_BJ).NewPresenceTracker(time.Minute)
+	polled := map[string]int{}
+	p.check = func(userId string) (int, error) {
+		polled[userId]++
+		return 1, nil
+	}
+	p.Track("u01", "u02")
+	events, cancel := p.Subscribe(10)
+	defer cancel()
+
+	callbacks, err := ParseOnlineStatusCallback([]byte(`[{"userid":"u01","status":"0","os":"iOS","time":100},
+		{"userid":"u01","status":"1","os":"iOS","time":50},{"userid":"u09","status":"0","time":100}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Apply(callbacks)
+	if online, known := p.IsOnline("u01"); !online || !known {
+		t.Errorf("u01: online %v known %v", online, known)
+	}
+	if _, known := p.IsOnline("u09"); known {
+		t.Error("untracked user was recorded")
+	}
+
+	if err := p.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if polled["u01"] != 0 || polled["u02"] != 1 {
+		t.Errorf("unexpected polls: %v", polled)
+	}
+	if got := p.Lookup("u01", "u02", "u03"); len(got) != 2 || !got["u02"] {
+		t.Errorf("unexpected lookup: %v", got)
+	}
+	if e := <-events; e.UserId != "u01" || e.Source != PresenceSourceCallback {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e := <-events; e.UserId != "u02" || e.Source != PresenceSourcePoll {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestPresenceTracker_ServeHTTP(t *testing.T) {
+	p := NewRongCloud("key", "secret", REGION_BJ).NewPresenceTracker(time.Minute)
+	p.Track("u01")
+	body := `[{"userid":"u01","status":"0","time":100}]`
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/presence?nonce=1&signTimestamp=2&signature=bad", strings.NewReader(body)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unexpected status for a bad signature: %d", rec.Code)
+	}
+
+	signature := fmt.Sprintf("%x", sha1.Sum([]byte("secret"+"1"+"2")))
+	rec = httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/presence?nonce=1&signTimestamp=2&signature="+signature, strings.NewReader(body)))
+	if online, _ := p.IsOnline("u01"); rec.Code != http.StatusOK || !online {
+		t.Errorf("callback not applied: %d %v", rec.Code, online)
+	}
+}
+
+func TestPresenceTracker_RefreshConcurrent(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		status := "0"
+		if strings.HasSuffix(r.FormValue("userId"), "1") {
+			status = "1"
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "status": "` + status + `"}`))
+	}))
+	defer server.Close()
+
+	p := newRongCloud("key", "secret", NewRegion(server.URL, "")).NewPresenceTracker(time.Minute)
+	userIds := make([]string, 3*PRESENCE_POLL_CONCURRENCY)
+	for i := range userIds {
+		userIds[i] = "u" + strconv.Itoa(i)
+	}
+	p.Track(userIds...)
+	if err := p.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); int(n) != len(userIds) {
+		t.Errorf("calls = %d", n)
+	}
+	for userId, online := range p.Lookup(userIds...) {
+		if online != strings.HasSuffix(userId, "1") {
+			t.Errorf("%s online = %v", userId, online)
+		}
+	}
+}
+
+func TestRongCloud_PresenceTrackerRefresh(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	p := rc.NewPresenceTracker(time.Minute)
+	p.Track("u01", "u02")
+	if err := p.Refresh(); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(p.Lookup("u01", "u02"))
+}
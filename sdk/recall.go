@@ -0,0 +1,233 @@
+// Recall service that remembers sent messages under business IDs
+
+package sdk
+
+import (
+	"sort"
+	"sync"
+)
+
+// RecallTarget One recipient of a recorded message
+type RecallTarget struct {
+	TargetId   string `json:"targetId"`   // User ID, group ID, chatroom ID or ultra group ID. Empty for broadcasts
+	MessageUID string `json:"messageUID"` // Message UID returned for this recipient
+}
+
+// RecallRecord A sent message as recorded by RecallService
+type RecallRecord struct {
+	BusinessId       string           `json:"businessId"`           // Caller's own message ID
+	ConversationType ConversationType `json:"conversationType"`     // ConversationTypePrivate, ConversationTypeGroup, CHATROOM, ConversationTypeSystem or ConversationTypeUG. Ignored for broadcasts
+	Broadcast        bool             `json:"broadcast,omitempty"`  // Message sent with MessageBroadcast, recalled with MessageBroadcastRecallByMessageUID
+	SenderId         string           `json:"senderId"`             // Sender user ID
+	SentTime         int64            `json:"sentTime"`             // Send timestamp in milliseconds
+	BusChannel       string           `json:"busChannel,omitempty"` // Ultra group channel ID
+	Targets          []RecallTarget   `json:"targets"`              // Recipients not recalled yet
+}
+
+// RecallStore Storage of RecallRecord keyed by business ID. Implementations must be safe for concurrent use.
+type RecallStore interface {
+	// Put saves the record, replacing any record with the same business ID
+	Put(record RecallRecord) error
+	// Get returns the record, and false when there is none
+	Get(businessId string) (RecallRecord, bool, error)
+	// Delete removes the record. Deleting a missing record is not an error
+	Delete(businessId string) error
+}
+
+// MemoryRecallStore RecallStore kept in process memory
+type MemoryRecallStore struct {
+	lock    sync.RWMutex
+	records map[string]RecallRecord
+}
+
+// NewMemoryRecallStore creates an empty MemoryRecallStore
+func NewMemoryRecallStore() *MemoryRecallStore {
+	return &MemoryRecallStore{records: map[string]RecallRecord{}}
+}
+
+// Put implements RecallStore
+func (s *MemoryRecallStore) Put(record RecallRecord) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record.Targets = append([]RecallTarget(nil), record.Targets...)
+	s.records[record.BusinessId] = record
+	return nil
+}
+
+// Get implements RecallStore
+func (s *MemoryRecallStore) Get(businessId string) (RecallRecord, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	record, ok := s.records[businessId]
+	record.Targets = append([]RecallTarget(nil), record.Targets...)
+	return record, ok, nil
+}
+
+// Delete implements RecallStore
+func (s *MemoryRecallStore) Delete(businessId string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.records, businessId)
+	return nil
+}
+
+// RecallMessage What RecallService needs to know about a send besides its MessageResult
+type RecallMessage struct {
+	ConversationType ConversationType // ConversationTypePrivate, ConversationTypeGroup, CHATROOM, ConversationTypeSystem or ConversationTypeUG
+	Broadcast        bool             // Set for MessageBroadcast sends
+	SenderId         string           // Sender user ID
+	TargetIds        []string         // Recipients in the order they were passed to the send call
+	SentTime         int64            // Send timestamp in milliseconds, taken right before the send call
+	BusChannel       string           // Ultra group channel ID
+}
+
+// RecallService Records sends under the caller's business IDs and recalls them later by business ID
+type RecallService struct {
+	rc    *RongCloud
+	store RecallStore
+}
+
+// NewRecallService creates a RecallService. A nil store uses a new MemoryRecallStore.
+func (rc *RongCloud) NewRecallService(store RecallStore) *RecallService {
+	if store == nil {
+		store = NewMemoryRecallStore()
+	}
+	return &RecallService{rc: rc, store: store}
+}
+
+// Record Stores the outcome of a send under businessId.
+// Per-recipient UIDs from MessageResult.MessageUIDs are matched to recipients through their userId, groupId or chatroomId.
+// A single MessageResult.MessageUID applies to every recipient in TargetIds.
+/*
+*@param  businessId: Caller's own message ID.
+*@param  msg: The send.
+*@param  result: MessageResult returned by the send call.
+*
+*@return RecallRecord, error
+ */
+func (s *RecallService) Record(businessId string, msg RecallMessage, result MessageResult) (RecallRecord, error) {
+	record := RecallRecord{
+		BusinessId:       businessId,
+		ConversationType: msg.ConversationType,
+		Broadcast:        msg.Broadcast,
+		SenderId:         msg.SenderId,
+		SentTime:         msg.SentTime,
+		BusChannel:       msg.BusChannel,
+	}
+	if businessId == "" {
+		return record, RCErrorNew(1002, "Paramer 'businessId' is required")
+	}
+	if msg.SenderId == "" {
+		return record, RCErrorNew(1002, "Paramer 'senderId' is required")
+	}
+	if msg.SentTime <= 0 {
+		return record, RCErrorNew(1002, "Paramer 'sentTime' is required")
+	}
+	if !msg.Broadcast && !validRecallType(msg.ConversationType) {
+		return record, RCErrorNew(1002, "Paramer 'conversationType' was wrong")
+	}
+
+	for _, entry := range result.MessageUIDs {
+		targetId := entry.UserId
+		if entry.GroupId != "" {
+			targetId = entry.GroupId
+		}
+		if entry.ChatroomId != "" {
+			targetId = entry.ChatroomId
+		}
+		record.Targets = append(record.Targets, RecallTarget{TargetId: targetId, MessageUID: entry.MessageUID})
+	}
+	if len(record.Targets) == 0 && result.MessageUID != "" {
+		if msg.Broadcast {
+			record.Targets = []RecallTarget{{MessageUID: result.MessageUID}}
+		} else {
+			for _, targetId := range msg.TargetIds {
+				record.Targets = append(record.Targets, RecallTarget{TargetId: targetId, MessageUID: result.MessageUID})
+			}
+		}
+	}
+	if len(record.Targets) == 0 {
+		return record, RCErrorNew(1002, "MessageResult holds no message UID")
+	}
+	sort.Slice(record.Targets, func(i, j int) bool {
+		return record.Targets[i].TargetId < record.Targets[j].TargetId
+	})
+	return record, s.store.Put(record)
+}
+
+// Lookup Returns the record stored under businessId, and false when there is none.
+func (s *RecallService) Lookup(businessId string) (RecallRecord, bool, error) {
+	return s.store.Get(businessId)
+}
+
+// Recall Recalls every recipient of the message stored under businessId with the recall API of its conversation type.
+// Recipients that are recalled are removed from the record, and the record is deleted once none are left,
+// so calling Recall again after an error only retries the remaining recipients.
+/*
+*@param  businessId: Caller's own message ID.
+*@param  options: Recall options such as WithIsAdmin, WithIsDelete and WithMsgDisablePush.
+*
+*@return map[string]error: Errors keyed by target ID of the recipients that could not be recalled.
+*@return error
+ */
+func (s *RecallService) Recall(businessId string, options ...MsgOption) (map[string]error, error) {
+	record, ok, err := s.store.Get(businessId)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, RCErrorNew(1002, "No message recorded for '"+businessId+"'")
+	}
+
+	failed := map[string]error{}
+	var remaining []RecallTarget
+	for _, target := range record.Targets {
+		if err := s.recall(record, target, options); err != nil {
+			failed[target.TargetId] = err
+			remaining = append(remaining, target)
+		}
+	}
+	if len(remaining) == 0 {
+		return failed, s.store.Delete(businessId)
+	}
+	record.Targets = remaining
+	if err := s.store.Put(record); err != nil {
+		return failed, err
+	}
+	return failed, RCErrorNew(1002, "Some recipients could not be recalled")
+}
+
+func (s *RecallService) recall(record RecallRecord, target RecallTarget, options []MsgOption) error {
+	rc := s.rc
+	sentTime := int(record.SentTime)
+	if record.Broadcast {
+		opts := modifyMsgOptions(options)
+		_, err := rc.MessageBroadcastRecallByMessageUID(record.SenderId, target.MessageUID, sentTime,
+			opts.isAdmin, opts.isDelete, "", options...)
+		return err
+	}
+	switch record.ConversationType {
+	case ConversationTypePrivate:
+		return rc.PrivateRecall(record.SenderId, target.TargetId, target.MessageUID, sentTime, options...)
+	case ConversationTypeGroup:
+		return rc.GroupRecall(record.SenderId, target.TargetId, target.MessageUID, sentTime, options...)
+	case CHATROOM:
+		return rc.ChatRoomRecall(record.SenderId, target.TargetId, target.MessageUID, sentTime, options...)
+	case ConversationTypeSystem:
+		return rc.SystemRecall(record.SenderId, target.TargetId, target.MessageUID, sentTime, options...)
+	case ConversationTypeUG:
+		if record.BusChannel != "" {
+			options = append([]MsgOption{WithMsgBusChannel(record.BusChannel)}, options...)
+		}
+		return rc.UGMessageRecall(record.SenderId, target.TargetId, target.MessageUID, sentTime, options...)
+	}
+	return RCErrorNew(1002, "Paramer 'conversationType' was wrong")
+}
+
+func validRecallType(t ConversationType) bool {
+	switch t {
+	case ConversationTypePrivate, ConversationTypeGroup, CHATROOM, ConversationTypeSystem, ConversationTypeUG:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecallService_Record(t *testing.T) {
+	s := NewRongCloud("key", "secret", REGION_BJ).NewRecallService(nil)
+
+	record, err := s.Record("biz-1", RecallMessage{
+		ConversationType: ConversationTypePrivate,
+		SenderId:         "u01",
+		TargetIds:        []string{"u03", "u02"},
+		SentTime:         1700000000000,
+	}, MessageResult{Code: 200, MessageUIDs: []MessageUIDEntry{
+		{UserId: "u03", MessageUID: "uid-3"},
+		{UserId: "u02", MessageUID: "uid-2"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RecallTarget{{TargetId: "u02", MessageUID: "uid-2"}, {TargetId: "u03", MessageUID: "uid-3"}}
+	if !reflect.DeepEqual(record.Targets, want) {
+		t.Errorf("unexpected targets: %+v", record.Targets)
+	}
+
+	record, err = s.Record("biz-2", RecallMessage{
+		ConversationType: ConversationTypeGroup,
+		SenderId:         "u01",
+		TargetIds:        []string{"g01"},
+		SentTime:         1700000000000,
+	}, MessageResult{Code: 200, MessageUID: "uid-g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, _ := s.Lookup("biz-2"); !ok || !reflect.DeepEqual(got, record) {
+		t.Errorf("unexpected lookup: %+v %v", got, ok)
+	}
+
+	if _, err = s.Record("biz-3", RecallMessage{ConversationType: ConversationTypePrivate, SenderId: "u01", SentTime: 1},
+		MessageResult{Code: 200}); err == nil {
+		t.Error("expected error without message UID")
+	}
+	if _, err = s.Recall("missing"); err == nil {
+		t.Error("expected error for unknown business ID")
+	}
+}
+
+func TestRecallService_Recall(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	s := rc.NewRecallService(nil)
+	sentTime := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := rc.PrivateSend("u01", []string{"u02"}, "RC:TxtMsg", &TXTMsg{Content: "hello"},
+		"", "", 1, 0, 1, 0, 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = s.Record("biz-1", RecallMessage{ConversationType: ConversationTypePrivate, SenderId: "u01",
+		TargetIds: []string{"u02"}, SentTime: sentTime}, result); err != nil {
+		t.Error(err)
+		return
+	}
+	failed, err := s.Recall("biz-1")
+	if err != nil {
+		t.Error(err, failed)
+	}
+}
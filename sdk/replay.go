@@ -0,0 +1,237 @@
+// Record and replay transport for tests that run without the live API
+
+package sdk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// REPLAY_SCRUBBED Replaces every secret in recorded bodies
+const REPLAY_SCRUBBED = "SCRUBBED"
+
+// Interaction One recorded request and its response. Request headers are not recorded, so the
+// App-Key, Nonce, Timestamp, Signature and X-Request-Id headers never reach the golden file.
+type Interaction struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Query       string `json:"query,omitempty"`       // Normalized query string
+	Body        string `json:"body,omitempty"`        // Normalized form or JSON body
+	Status      int    `json:"status"`                // Response status code
+	ContentType string `json:"contentType,omitempty"` // Response Content-Type
+	Response    string `json:"response"`              // Response body
+}
+
+// ReplayTransport An http.RoundTripper that records interactions with the API to a golden file, or replays them.
+// Plug it in with WithTransport or SetHttpTransport. Requests are matched on method, path and normalized
+// query and body, so the domain, parameter order, JSON key order and signing headers do not matter.
+type ReplayTransport struct {
+	path         string
+	next         http.RoundTripper // Transport used while recording, nil when replaying
+	secrets      []string
+	lock         sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecordTransport creates a ReplayTransport that sends requests through next and records them. Call Save to write the file.
+/*
+*@param  path: Golden file.
+*@param  next: Transport that reaches the API, nil uses http.DefaultTransport.
+*@param  secrets: Values replaced by REPLAY_SCRUBBED in recorded bodies, such as the App Key, App Secret and tokens.
+*
+*@return *ReplayTransport
+ */
+func NewRecordTransport(path string, next http.RoundTripper, secrets ...string) *ReplayTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &ReplayTransport{path: path, next: next, secrets: replaySecrets(secrets)}
+}
+
+// NewReplayTransport creates a ReplayTransport that answers requests from a golden file.
+/*
+*@param  path: Golden file written by Save.
+*@param  secrets: The secrets passed when recording, so that requests containing them still match.
+*
+*@return *ReplayTransport, error
+ */
+func NewReplayTransport(path string, secrets ...string) (*ReplayTransport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t := &ReplayTransport{path: path, secrets: replaySecrets(secrets)}
+	if err := json.Unmarshal(data, &t.interactions); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	t.used = make([]bool, len(t.interactions))
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	key := Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  t.scrub(replayNormalizeForm(req.URL.RawQuery)),
+		Body:   t.scrub(replayNormalizeBody(req.Header.Get("Content-Type"), body)),
+	}
+	if t.next != nil {
+		return t.record(req, key)
+	}
+	return t.replay(req, key)
+}
+
+func (t *ReplayTransport) record(req *http.Request, key Interaction) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		reader = gz
+		resp.Header.Del("Content-Encoding")
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	key.Status, key.ContentType, key.Response = resp.StatusCode, resp.Header.Get("Content-Type"), t.scrub(string(data))
+	t.lock.Lock()
+	t.interactions = append(t.interactions, key)
+	t.lock.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	return resp, nil
+}
+
+// replay answers with the first unused matching interaction, or with the last matching one when all were used.
+func (t *ReplayTransport) replay(req *http.Request, key Interaction) (*http.Response, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	found := -1
+	for i, in := range t.interactions {
+		if in.Method != key.Method || in.Path != key.Path || in.Query != key.Query || in.Body != key.Body {
+			continue
+		}
+		found = i
+		if !t.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("replay: no recorded interaction for %s %s %s in %s", key.Method, key.Path, key.Body, t.path)
+	}
+	t.used[found] = true
+	in := t.interactions[found]
+	header := http.Header{}
+	if in.ContentType != "" {
+		header.Set("Content-Type", in.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(in.Response)),
+		ContentLength: int64(len(in.Response)),
+		Request:       req,
+	}, nil
+}
+
+// Interactions Returns a copy of the recorded or loaded interactions.
+func (t *ReplayTransport) Interactions() []Interaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]Interaction(nil), t.interactions...)
+}
+
+// Save Writes the recorded interactions to the golden file as indented JSON.
+func (t *ReplayTransport) Save() error {
+	data, err := json.MarshalIndent(t.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, append(data, '\n'), os.FileMode(0644))
+}
+
+func (t *ReplayTransport) scrub(s string) string {
+	for _, secret := range t.secrets {
+		s = strings.Replace(s, secret, REPLAY_SCRUBBED, -1)
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			s = strings.Replace(s, escaped, REPLAY_SCRUBBED, -1)
+		}
+	}
+	return s
+}
+
+func replaySecrets(secrets []string) []string {
+	var kept []string
+	for _, secret := range secrets {
+		if secret != "" {
+			kept = append(kept, secret)
+		}
+	}
+	return kept
+}
+
+// replayNormalizeBody sorts form parameters and JSON object keys, so that equivalent bodies compare equal.
+func replayNormalizeBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return replayNormalizeForm(string(body))
+	case "application/json":
+		var v interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err == nil {
+			if normalized, err := json.Marshal(v); err == nil {
+				return string(normalized)
+			}
+		}
+	}
+	return string(body)
+}
+
+// replayNormalizeForm sorts the parameters of a form or query string by key, keeping the order of repeated keys.
+func replayNormalizeForm(s string) string {
+	if s == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return s
+	}
+	return values.Encode()
+}
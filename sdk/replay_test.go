@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withReplay points the shared client at transport and returns a function that restores it.
+func withReplay(transport http.RoundTripper, uri string) (*RongCloud, func()) {
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	oldTransport, oldURI := rc.GetHttpTransport(), rc.rongCloudURI
+	rc.SetHttpTransport(transport)
+	rc.PrivateURI(uri)
+	return rc, func() {
+		rc.SetHttpTransport(oldTransport)
+		rc.PrivateURI(oldURI)
+	}
+}
+
+func TestReplayTransport_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/user/getToken.json":
+			_ = r.ParseForm()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "userId": r.Form.Get("userId"), "token": "tok-123"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 10000, "data": "ok"})
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "golden.json")
+
+	recorder := NewRecordTransport(golden, nil, "tok-123")
+	rc, restore := withReplay(recorder, server.URL)
+	defer restore()
+	if _, err := rc.UserRegister("u01", "Alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.Call(APIRequest{Path: "/v3/things", Version: APIVersionV2, Body: map[string]interface{}{"b": 1, "a": "x"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(golden)
+	if strings.Contains(string(data), "tok-123") || strings.Contains(string(data), "Signature") {
+		t.Errorf("secrets left in golden file:\n%s", data)
+	}
+
+	player, err := NewReplayTransport(golden, "tok-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, restore = withReplay(player, "http://replay.invalid")
+	defer restore()
+	user, err := rc.UserRegister("u01", "Alice", "")
+	if err != nil || user.UserID != "u01" || user.Token != REPLAY_SCRUBBED {
+		t.Errorf("unexpected replay: %+v %v", user, err)
+	}
+	// JSON key order does not matter.
+	if _, err := rc.Call(APIRequest{Path: "/v3/things", Version: APIVersionV2, Body: map[string]interface{}{"a": "x", "b": 1}}, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := rc.UserRegister("u02", "Bob", ""); err == nil {
+		t.Error("expected error for a request that was not recorded")
+	}
+}
+
+func TestRongCloud_UserRegisterReplay(t *testing.T) {
+	player, err := NewReplayTransport(filepath.Join("testdata", "user_register.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, restore := withReplay(player, REGION_BJ.primaryDomain)
+	defer restore()
+	user, err := rc.UserRegister("u01", "Alice", "https://example.com/a.png")
+	if err != nil || user.UserID != "u01" {
+		t.Errorf("unexpected result: %+v %v", user, err)
+	}
+}
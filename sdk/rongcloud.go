@@ -39,13 +39,12 @@
 package sdk
 
 import (
-	"crypto/sha1"
-	"fmt"
-	"io"
-	"math/rand"
+	"crypto/tls"
 	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -92,6 +91,31 @@ type Region struct {
 	backupDomain  string
 }
 
+// NewRegion creates a Region for custom API domains, such as a private cloud deployment
+func NewRegion(primaryDomain, backupDomain string) Region {
+	if backupDomain == "" {
+		backupDomain = primaryDomain
+	}
+	return Region{primaryDomain: primaryDomain, backupDomain: backupDomain}
+}
+
+// RegionByName returns the region for "bj", "sg", "sg_b", "na" or "sau", case insensitive
+func RegionByName(name string) (Region, bool) {
+	switch strings.ToLower(name) {
+	case "bj":
+		return REGION_BJ, true
+	case "sg":
+		return REGION_SG, true
+	case "sg_b":
+		return REGION_SG_B, true
+	case "na":
+		return REGION_NA, true
+	case "sau":
+		return REGION_SAU, true
+	}
+	return Region{}, false
+}
+
 // RongCloud appKey appSecret extra
 type RongCloud struct {
 	appKey        string
@@ -100,8 +124,12 @@ type RongCloud struct {
 	backupDomain  string
 	rongCloudURI  string
 	*rongCloudExtra
-	uriLock         sync.Mutex
-	globalTransport http.RoundTripper
+	uriLock            sync.Mutex
+	globalTransport    http.RoundTripper
+	credentialProvider CredentialProvider
+	idempotencyCache   IdempotencyCache
+	idempotencyTTL     time.Duration
+	circuitBreaker     *CircuitBreaker
 }
 
 // rongCloudExtra extends RongCloud with custom RongCloud server address and request timeout
@@ -118,18 +146,15 @@ type rongCloudExtra struct {
 // Signature calculation method: Concatenate the App Secret, Nonce (random number),
 // and Timestamp (Unix timestamp) in order, then compute the SHA1 hash. If the signature verification fails, the API call will return HTTP status code 401.
 func (rc RongCloud) getSignature() (nonce, timestamp, signature string) {
-	nonceInt := rand.Int()
-	nonce = strconv.Itoa(nonceInt)
+	nonce = NewNonce()
 	timeInt64 := time.Now().Unix()
 	timestamp = strconv.FormatInt(timeInt64, 10)
-	h := sha1.New()
-	_, _ = io.WriteString(h, rc.appSecret+nonce+timestamp)
-	signature = fmt.Sprintf("%x", h.Sum(nil))
+	signature = Sign(rc.credentials().AppSecret, nonce, timestamp)
 	return
 }
 
-// fillHeader adds API signature to the Http Header
-func (rc RongCloud) fillHeader(req *httplib.BeegoHTTPRequest) {
+// fillHeader adds API signature to the Http Header and returns the X-Request-Id
+func (rc RongCloud) fillHeader(req *httplib.BeegoHTTPRequest) string {
 	requestId := uuid.New().String()
 	req.Header("Content-Type", "application/x-www-form-urlencoded")
 	req.Header("User-Agent", USERAGENT)
@@ -139,6 +164,7 @@ func (rc RongCloud) fillHeader(req *httplib.BeegoHTTPRequest) {
 	req.Header("Nonce", nonce)
 	req.Header("Timestamp", timestamp)
 	req.Header("Signature", signature)
+	return requestId
 }
 
 // v2 sdk header
@@ -163,34 +189,53 @@ func fillJSONHeader(req *httplib.BeegoHTTPRequest) {
 // NewRongCloud creates a RongCloud object
 func NewRongCloud(appKey, appSecret string, region Region, options ...rongCloudOption) *RongCloud {
 	once.Do(func() {
-		// Default extended configuration
-		defaultRongCloud := defaultExtra
-		defaultRongCloud.lastChageUriTime = 0
-		rc = &RongCloud{
-			appKey:         appKey,
-			appSecret:      appSecret,
-			rongCloudURI:   region.primaryDomain,
-			primaryDomain:  region.primaryDomain,
-			backupDomain:   region.backupDomain,
-			rongCloudExtra: &defaultRongCloud,
-		}
+		rc = newRongCloud(appKey, appSecret, region, options...)
+	})
 
-		for _, option := range options {
-			option(rc)
-		}
+	return rc
+}
+
+// newRongCloud creates a RongCloud object that is not shared through GetRongCloud
+func newRongCloud(appKey, appSecret string, region Region, options ...rongCloudOption) *RongCloud {
+	// Default extended configuration
+	defaultRongCloud := defaultExtra
+	defaultRongCloud.lastChageUriTime = 0
+	client := &RongCloud{
+		appKey:           appKey,
+		appSecret:        appSecret,
+		rongCloudURI:     region.primaryDomain,
+		primaryDomain:    region.primaryDomain,
+		backupDomain:     region.backupDomain,
+		rongCloudExtra:   &defaultRongCloud,
+		idempotencyCache: NewMemoryIdempotencyCache(),
+		idempotencyTTL:   IDEMPOTENCY_TTL,
+	}
+
+	for _, option := range options {
+		option(client)
+	}
 
-		if rc.globalTransport == nil {
-			rc.globalTransport = &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout:   rc.timeout * time.Second,
-					KeepAlive: rc.keepAlive * time.Second,
-				}).DialContext,
-				MaxIdleConnsPerHost: rc.maxIdleConnsPerHost,
-			}
+	if client.globalTransport == nil {
+		dialer := &net.Dialer{
+			Timeout:   client.timeout * time.Second,
+			KeepAlive: client.keepAlive * time.Second,
 		}
-	})
+		// httplib fills TLSClientConfig, Proxy and Dial of the transport on every request when they are nil,
+		// so they are set here to keep concurrent requests from writing to the shared transport.
+		client.globalTransport = &http.Transport{
+			TLSClientConfig:     &tls.Config{},
+			Proxy:               noProxy,
+			Dial:                dialer.Dial,
+			DialContext:         dialer.DialContext,
+			MaxIdleConnsPerHost: client.maxIdleConnsPerHost,
+		}
+	}
+	return client
+}
 
-	return rc
+// noProxy sends requests directly, as a transport without Proxy does
+func noProxy(*http.Request) (*url.URL, error) {
+	return nil, nil
 }
 
 // GetRongCloud retrieves the RongCloud object
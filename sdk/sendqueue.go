@@ -0,0 +1,293 @@
+// Asynchronous send queue on top of the message and push APIs
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// SEND_QUEUE_WORKERS Default number of workers of a SendQueue
+	SEND_QUEUE_WORKERS = 4
+	// SEND_QUEUE_CAPACITY Default number of jobs a SendQueue holds before Enqueue blocks
+	SEND_QUEUE_CAPACITY = 1024
+)
+
+var (
+	// ErrSendQueueFull Returned by TryEnqueue when the queue of the job is full
+	ErrSendQueueFull = errors.New("send queue is full")
+	// ErrSendQueueClosed Returned when a job is enqueued after Close
+	ErrSendQueueClosed = errors.New("send queue is closed")
+)
+
+// SendJob One send handled by a SendQueue. Set either Send or Push.
+type SendJob struct {
+	Id   string                                     // Caller's ID, copied to the SendResult
+	Key  string                                     // Ordering key, jobs with the same key are sent one after the other in enqueue order. See SendKey
+	Send func(rc *RongCloud) (MessageResult, error) // Message send, such as a call to PrivateSend or GroupSend
+	Push Sender                                     // Push sent with PushSend when Send is nil
+}
+
+// SendResult Outcome of a SendJob
+type SendResult struct {
+	Id     string
+	Key    string
+	Result MessageResult // Result of a Send job
+	Push   PushResult    // Result of a Push job
+	Err    error
+	Wait   time.Duration // Time spent in the queue
+}
+
+// SendQueueStats Counters of a SendQueue
+type SendQueueStats struct {
+	Depth     int   // Jobs waiting in the queue
+	MaxDepth  int   // Highest Depth seen
+	Capacity  int   // Jobs the queue holds before Enqueue blocks
+	InFlight  int   // Jobs being sent
+	Enqueued  int64 // Jobs accepted since creation
+	Succeeded int64
+	Failed    int64
+}
+
+// SendQueue Sends messages and pushes from a pool of workers. Jobs are spread over the workers by their key,
+// so jobs with the same key are sent in order by the same worker, while a slow key may delay other keys of that worker.
+// Every worker has its own bounded buffer; when it is full, Enqueue blocks and TryEnqueue fails.
+type SendQueue struct {
+	enqueued  int64
+	succeeded int64
+	failed    int64
+	inFlight  int64
+	maxDepth  int64
+	next      uint32
+
+	rc       *RongCloud
+	workers  int
+	capacity int
+	callback func(SendResult)
+	results  chan<- SendResult
+
+	lock   sync.RWMutex
+	closed bool
+	queues []chan queuedJob
+	wg     sync.WaitGroup
+}
+
+type queuedJob struct {
+	job      SendJob
+	enqueued time.Time
+}
+
+// SendQueueOption Functional option of NewSendQueue
+type SendQueueOption func(*SendQueue)
+
+// WithSendQueueWorkers sets the number of workers, default SEND_QUEUE_WORKERS
+func WithSendQueueWorkers(n int) SendQueueOption {
+	return func(q *SendQueue) {
+		q.workers = n
+	}
+}
+
+// WithSendQueueCapacity sets the number of jobs held before Enqueue blocks, default SEND_QUEUE_CAPACITY.
+// It is split evenly between the workers.
+func WithSendQueueCapacity(n int) SendQueueOption {
+	return func(q *SendQueue) {
+		q.capacity = n
+	}
+}
+
+// WithSendQueueCallback sets a function called with every result, from the worker that sent the job
+func WithSendQueueCallback(callback func(SendResult)) SendQueueOption {
+	return func(q *SendQueue) {
+		q.callback = callback
+	}
+}
+
+// WithSendQueueResults sets a channel that receives every result. The workers wait while it is full,
+// so it must be read until Close returns.
+func WithSendQueueResults(results chan<- SendResult) SendQueueOption {
+	return func(q *SendQueue) {
+		q.results = results
+	}
+}
+
+// NewSendQueue creates a SendQueue and starts its workers. Call Close to stop them.
+func (rc *RongCloud) NewSendQueue(options ...SendQueueOption) *SendQueue {
+	q := &SendQueue{rc: rc, workers: SEND_QUEUE_WORKERS, capacity: SEND_QUEUE_CAPACITY}
+	for _, option := range options {
+		option(q)
+	}
+	if q.workers < 1 {
+		q.workers = 1
+	}
+	perWorker := (q.capacity + q.workers - 1) / q.workers
+	if perWorker < 1 {
+		perWorker = 1
+	}
+	q.capacity = perWorker * q.workers
+	q.queues = make([]chan queuedJob, q.workers)
+	for i := range q.queues {
+		q.queues[i] = make(chan queuedJob, perWorker)
+		q.wg.Add(1)
+		go q.work(q.queues[i])
+	}
+	return q
+}
+
+// SendKey Returns the ordering key of a conversation: sender and recipients for private and system messages,
+// the recipients for group, chatroom and ultra group messages.
+func SendKey(conversationType ConversationType, senderId string, targetIds ...string) string {
+	targets := strings.Join(targetIds, ",")
+	switch conversationType {
+	case ConversationTypePrivate, ConversationTypeSystem:
+		return fmt.Sprintf("%d:%s:%s", conversationType, senderId, targets)
+	}
+	return fmt.Sprintf("%d:%s", conversationType, targets)
+}
+
+// Enqueue Adds a job, waiting while its queue is full.
+/*
+*@param  ctx: Ends the wait for space in the queue.
+*@param  job: The send.
+*
+*@return error: ErrSendQueueClosed, or the error of ctx.
+ */
+func (q *SendQueue) Enqueue(ctx context.Context, job SendJob) error {
+	return q.enqueue(ctx, job, true)
+}
+
+// TryEnqueue Adds a job, or returns ErrSendQueueFull at once when its queue is full.
+func (q *SendQueue) TryEnqueue(job SendJob) error {
+	return q.enqueue(context.Background(), job, false)
+}
+
+func (q *SendQueue) enqueue(ctx context.Context, job SendJob, wait bool) error {
+	if job.Send == nil && job.Push == nil {
+		return RCErrorNew(1002, "Paramer 'send' is required")
+	}
+	// The read lock keeps Close from closing the channel while a job is being added.
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.closed {
+		return ErrSendQueueClosed
+	}
+	queue := q.queues[q.shard(job.Key)]
+	item := queuedJob{job: job, enqueued: time.Now()}
+	if wait {
+		select {
+		case queue <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	} else {
+		select {
+		case queue <- item:
+		default:
+			return ErrSendQueueFull
+		}
+	}
+	atomic.AddInt64(&q.enqueued, 1)
+	q.updateMaxDepth()
+	return nil
+}
+
+// shard picks the worker of a key. Jobs without a key are spread round robin.
+func (q *SendQueue) shard(key string) int {
+	if key == "" {
+		return int(atomic.AddUint32(&q.next, 1) % uint32(q.workers))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(q.workers))
+}
+
+func (q *SendQueue) depth() int {
+	depth := 0
+	for _, queue := range q.queues {
+		depth += len(queue)
+	}
+	return depth
+}
+
+func (q *SendQueue) updateMaxDepth() {
+	depth := int64(q.depth())
+	for {
+		max := atomic.LoadInt64(&q.maxDepth)
+		if depth <= max || atomic.CompareAndSwapInt64(&q.maxDepth, max, depth) {
+			return
+		}
+	}
+}
+
+func (q *SendQueue) work(queue <-chan queuedJob) {
+	defer q.wg.Done()
+	for item := range queue {
+		atomic.AddInt64(&q.inFlight, 1)
+		res := SendResult{Id: item.job.Id, Key: item.job.Key, Wait: time.Since(item.enqueued)}
+		if item.job.Send != nil {
+			res.Result, res.Err = item.job.Send(q.rc)
+		} else {
+			res.Push, res.Err = q.rc.PushSend(item.job.Push)
+		}
+		atomic.AddInt64(&q.inFlight, -1)
+		if res.Err != nil {
+			atomic.AddInt64(&q.failed, 1)
+		} else {
+			atomic.AddInt64(&q.succeeded, 1)
+		}
+		if q.callback != nil {
+			q.callback(res)
+		}
+		if q.results != nil {
+			q.results <- res
+		}
+	}
+}
+
+// Stats Returns the current counters.
+func (q *SendQueue) Stats() SendQueueStats {
+	return SendQueueStats{
+		Depth:     q.depth(),
+		MaxDepth:  int(atomic.LoadInt64(&q.maxDepth)),
+		Capacity:  q.capacity,
+		InFlight:  int(atomic.LoadInt64(&q.inFlight)),
+		Enqueued:  atomic.LoadInt64(&q.enqueued),
+		Succeeded: atomic.LoadInt64(&q.succeeded),
+		Failed:    atomic.LoadInt64(&q.failed),
+	}
+}
+
+// Close Stops accepting jobs and waits until the queued jobs are sent.
+/*
+*@param  ctx: Ends the wait. The workers keep draining the queue in the background.
+*
+*@return error: nil when every job was sent, or the error of ctx.
+ */
+func (q *SendQueue) Close(ctx context.Context) error {
+	q.lock.Lock()
+	if !q.closed {
+		q.closed = true
+		for _, queue := range q.queues {
+			close(queue)
+		}
+	}
+	q.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendQueue_Order(t *testing.T) {
+	var lock sync.Mutex
+	sent := map[string][]int{}
+	results := make(chan SendResult, 100)
+	q := newRongCloud("key", "secret", REGION_BJ).NewSendQueue(WithSendQueueWorkers(3), WithSendQueueCapacity(10), WithSendQueueResults(results))
+
+	keys := []string{SendKey(ConversationTypePrivate, "u01", "u02"), SendKey(ConversationTypeGroup, "u01", "g01"), SendKey(CHATROOM, "u01", "r01")}
+	for i := 0; i < 60; i++ {
+		key, n := keys[i%len(keys)], i
+		job := SendJob{Id: fmt.Sprint(n), Key: key, Send: func(rc *RongCloud) (MessageResult, error) {
+			time.Sleep(time.Millisecond)
+			lock.Lock()
+			sent[key] = append(sent[key], n)
+			lock.Unlock()
+			if n == 7 {
+				return MessageResult{}, errors.New("rejected")
+			}
+			return MessageResult{Code: 200, MessageUID: fmt.Sprint("uid-", n)}, nil
+		}}
+		if err := q.Enqueue(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	close(results)
+
+	for key, ns := range sent {
+		for i := 1; i < len(ns); i++ {
+			if ns[i] < ns[i-1] {
+				t.Errorf("%s sent out of order: %v", key, ns)
+				break
+			}
+		}
+	}
+	count := 0
+	for res := range results {
+		count++
+		if (res.Err != nil) != (res.Id == "7") || (res.Err == nil && res.Result.MessageUID != "uid-"+res.Id) {
+			t.Errorf("result = %+v", res)
+		}
+	}
+	stats := q.Stats()
+	if count != 60 || stats.Enqueued != 60 || stats.Succeeded != 59 || stats.Failed != 1 || stats.Depth != 0 || stats.Capacity != 12 {
+		t.Errorf("count = %d, stats = %+v", count, stats)
+	}
+	if err := q.TryEnqueue(SendJob{Send: sendOK}); err != ErrSendQueueClosed {
+		t.Errorf("enqueue after close: %v", err)
+	}
+}
+
+func sendOK(rc *RongCloud) (MessageResult, error) {
+	return MessageResult{Code: 200}, nil
+}
+
+func TestSendQueue_Backpressure(t *testing.T) {
+	release := make(chan struct{})
+	block := func(rc *RongCloud) (MessageResult, error) {
+		<-release
+		return MessageResult{Code: 200}, nil
+	}
+	q := newRongCloud("key", "secret", REGION_BJ).NewSendQueue(WithSendQueueWorkers(1), WithSendQueueCapacity(2))
+
+	// One job is taken by the worker, two fill the buffer.
+	for i := 0; i < 3; i++ {
+		if err := q.Enqueue(context.Background(), SendJob{Key: "k", Send: block}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			for q.Stats().InFlight == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+	if err := q.TryEnqueue(SendJob{Key: "k", Send: block}); err != ErrSendQueueFull {
+		t.Errorf("TryEnqueue = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Enqueue(ctx, SendJob{Key: "k", Send: block}); err != context.DeadlineExceeded {
+		t.Errorf("Enqueue = %v", err)
+	}
+	if stats := q.Stats(); stats.Depth != 2 || stats.MaxDepth != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Close while blocked = %v", err)
+	}
+	close(release)
+	if err := q.Close(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if stats := q.Stats(); stats.Succeeded != 3 {
+		t.Errorf("stats after drain = %+v", stats)
+	}
+}
+
+func TestSendQueue_Push(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/push.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code": 200, "id": "push-1"}`))
+	}))
+	defer server.Close()
+
+	var got []SendResult
+	client := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+	q := client.NewSendQueue(WithSendQueueCallback(func(res SendResult) { got = append(got, res) }), WithSendQueueWorkers(1))
+	if err := q.TryEnqueue(SendJob{Id: "p1", Push: Push{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.TryEnqueue(SendJob{Id: "none"}); err == nil {
+		t.Error("job without send accepted")
+	}
+	if err := q.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Err != nil || got[0].Push.ID != "push-1" {
+		t.Errorf("results = %+v", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Signature utilities for services that sign or verify requests and callbacks the way RongCloud does
+
+package sdk
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SIGNATURE_MAX_SKEW Suggested maximum difference between a signed timestamp and the local clock
+const SIGNATURE_MAX_SKEW = 5 * time.Minute
+
+// Sign Computes the Signature header: the hex SHA1 of App Secret, Nonce and Timestamp concatenated in order.
+func Sign(appSecret, nonce, timestamp string) string {
+	h := sha1.New()
+	_, _ = io.WriteString(h, appSecret+nonce+timestamp)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// VerifySignature Checks a signature in constant time.
+func VerifySignature(appSecret, nonce, timestamp, signature string) bool {
+	if appSecret == "" || nonce == "" || timestamp == "" || signature == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(Sign(appSecret, nonce, timestamp)), []byte(signature)) == 1
+}
+
+// NewNonce Returns a random nonce from crypto/rand.
+func NewNonce() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		// crypto/rand only fails when the system has no entropy source, which is not recoverable.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// CheckTimestamp Checks that a signed timestamp is within maxSkew of now.
+/*
+*@param  timestamp: Unix time in seconds, as in the Timestamp header, or in milliseconds, as in the signTimestamp of callbacks.
+*@param  now: Local time.
+*@param  maxSkew: Accepted difference in either direction, such as SIGNATURE_MAX_SKEW.
+*
+*@return error
+ */
+func CheckTimestamp(timestamp string, now time.Time, maxSkew time.Duration) error {
+	n, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || n <= 0 {
+		return RCErrorNew(1002, "Paramer 'timestamp' was wrong")
+	}
+	var t time.Time
+	// Seconds stay below 1e12 until the year 33658.
+	if n >= 1e12 {
+		t = time.Unix(0, n*int64(time.Millisecond))
+	} else {
+		t = time.Unix(n, 0)
+	}
+	if skew := now.Sub(t); math.Abs(float64(skew)) > float64(maxSkew) {
+		return RCErrorNew(1002, "Paramer 'timestamp' is out of range")
+	}
+	return nil
+}
+
+// VerifyRequest Checks the Nonce, Timestamp and Signature headers of a request signed like a RongCloud API request.
+// Both secrets of creds are accepted, so that clients may sign with either during a rotation.
+/*
+*@param  r: Incoming request.
+*@param  creds: Secrets of the app, PreviousAppSecret may be empty.
+*@param  maxSkew: Accepted clock difference, 0 skips the timestamp check.
+*
+*@return error: nil when the request is signed correctly.
+ */
+func VerifyRequest(r *http.Request, creds Credentials, maxSkew time.Duration) error {
+	return verifySigned(r.Header.Get("Nonce"), r.Header.Get("Timestamp"), r.Header.Get("Signature"), creds, maxSkew)
+}
+
+// VerifyCallback Checks the nonce, signTimestamp and signature query parameters of a callback sent by RongCloud.
+/*
+*@param  r: Incoming callback request.
+*@param  creds: Secrets of the app, PreviousAppSecret may be empty.
+*@param  maxSkew: Accepted clock difference, 0 skips the timestamp check.
+*
+*@return error: nil when the callback is signed correctly.
+ */
+func VerifyCallback(r *http.Request, creds Credentials, maxSkew time.Duration) error {
+	query := r.URL.Query()
+	return verifySigned(query.Get("nonce"), query.Get("signTimestamp"), query.Get("signature"), creds, maxSkew)
+}
+
+func verifySigned(nonce, timestamp, signature string, creds Credentials, maxSkew time.Duration) error {
+	if nonce == "" || timestamp == "" || signature == "" {
+		return RCErrorNew(1004, "Signature is required")
+	}
+	if maxSkew > 0 {
+		if err := CheckTimestamp(timestamp, time.Now(), maxSkew); err != nil {
+			return err
+		}
+	}
+	if !VerifySignature(creds.AppSecret, nonce, timestamp, signature) && !VerifySignature(creds.PreviousAppSecret, nonce, timestamp, signature) {
+		return RCErrorNew(1004, "Incorrect signature")
+	}
+	return nil
+}
+
+// SignRequest Sets the App-Key, Nonce, Timestamp and Signature headers of a request.
+func SignRequest(req *http.Request, appKey, appSecret string) {
+	nonce, timestamp := NewNonce(), strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("App-Key", appKey)
+	req.Header.Set("Nonce", nonce)
+	req.Header.Set("Timestamp", timestamp)
+	req.Header.Set("Signature", Sign(appSecret, nonce, timestamp))
+}
+
+// SigningTransport An http.RoundTripper that signs every request with SignRequest before sending it
+type SigningTransport struct {
+	next     http.RoundTripper
+	appKey   string
+	provider CredentialProvider
+}
+
+// NewSigningTransport creates a SigningTransport
+/*
+*@param  next: Transport that sends the signed requests, nil uses http.DefaultTransport.
+*@param  appKey: App Key sent in the App-Key header.
+*@param  provider: Source of the App Secret, such as NewStaticCredentialProvider(appSecret).
+*
+*@return *SigningTransport
+ */
+func NewSigningTransport(next http.RoundTripper, appKey string, provider CredentialProvider) *SigningTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &SigningTransport{next: next, appKey: appKey, provider: provider}
+}
+
+// RoundTrip implements http.RoundTripper. The request passed in is not modified.
+func (t *SigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds, err := t.provider.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	if creds.AppSecret == "" {
+		return nil, RCErrorNew(1002, "Paramer 'appSecret' is required")
+	}
+	signed := req.Clone(req.Context())
+	SignRequest(signed, t.appKey, creds.AppSecret)
+	return t.next.RoundTrip(signed)
+}
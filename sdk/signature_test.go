@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// sha1("secret" + "nonce" + "1700000000")
+	if got := Sign("secret", "nonce", "1700000000"); got != "c0b87c883bf8a70941d7e81c14cc7c7e7ccd3ba2" {
+		t.Errorf("signature = %s", got)
+	}
+	if !VerifySignature("secret", "n", "1", Sign("secret", "n", "1")) {
+		t.Error("valid signature rejected")
+	}
+	if VerifySignature("secret", "n", "1", Sign("other", "n", "1")) || VerifySignature("", "n", "1", Sign("", "n", "1")) {
+		t.Error("invalid signature accepted")
+	}
+	if a, b := NewNonce(), NewNonce(); a == b || len(a) != 32 {
+		t.Errorf("nonces = %s, %s", a, b)
+	}
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for timestamp, ok := range map[string]bool{
+		"1700000000":    true,
+		"1700000299":    true,
+		"1699999701":    true,
+		"1700000301":    false,
+		"1700000000123": true,
+		"1699999000000": false,
+		"soon":          false,
+		"-1":            false,
+	} {
+		if err := CheckTimestamp(timestamp, now, SIGNATURE_MAX_SKEW); (err == nil) != ok {
+			t.Errorf("%s: err = %v", timestamp, err)
+		}
+	}
+}
+
+func TestSigningTransport(t *testing.T) {
+	creds := Credentials{AppSecret: "new", PreviousAppSecret: "old"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("App-Key") != "key" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := VerifyRequest(r, creds, SIGNATURE_MAX_SKEW); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	for secret, want := range map[string]int{"new": http.StatusOK, "old": http.StatusOK, "other": http.StatusUnauthorized} {
+		client := &http.Client{Transport: NewSigningTransport(nil, "key", NewStaticCredentialProvider(secret))}
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: status = %d", secret, resp.StatusCode)
+		}
+		if req.Header.Get("Signature") != "" {
+			t.Error("original request modified")
+		}
+	}
+
+	// Unsigned requests are rejected.
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("App-Key", "key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned status = %d", resp.StatusCode)
+	}
+}
+
+func TestVerifyCallback(t *testing.T) {
+	ts := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	req := httptest.NewRequest(http.MethodPost, "/callback?nonce=n&signTimestamp="+ts+"&signature="+Sign("secret", "n", ts), nil)
+	if err := VerifyCallback(req, Credentials{AppSecret: "secret"}, SIGNATURE_MAX_SKEW); err != nil {
+		t.Error(err)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/callback?nonce=n&signTimestamp=1000&signature="+Sign("secret", "n", "1000"), nil)
+	if err := VerifyCallback(req, Credentials{AppSecret: "secret"}, SIGNATURE_MAX_SKEW); err == nil {
+		t.Error("stale callback accepted")
+	}
+	if err := VerifyCallback(req, Credentials{AppSecret: "secret"}, 0); err != nil {
+		t.Errorf("skew check not skipped: %v", err)
+	}
+}
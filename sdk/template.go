@@ -0,0 +1,284 @@
+// Named message templates rendered and validated locally before PrivateSendTemplate and SystemSendTemplate
+
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// TEMPLATE_PRIVATE_MAX_USERS Maximum number of recipients per PrivateSendTemplate request
+	TEMPLATE_PRIVATE_MAX_USERS = 1000
+	// TEMPLATE_SYSTEM_MAX_USERS Maximum number of recipients per SystemSendTemplate request
+	TEMPLATE_SYSTEM_MAX_USERS = 100
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{([^{}\s]+)\}`)
+
+// MessageTemplate A named template. {key} placeholders in Content, PushContent and PushData are replaced per recipient.
+type MessageTemplate struct {
+	Name        string `json:"name"`        // Template name (required)
+	Locale      string `json:"locale"`      // Locale such as "en" or "zh-CN", empty for the default variant
+	ObjectName  string `json:"objectName"`  // Message type, defaults to RC:TxtMsg
+	Content     TXTMsg `json:"content"`     // Message template sent to the server
+	PushContent string `json:"pushContent"` // Push notification template, rendered locally
+	PushData    string `json:"pushData"`    // Push data template, rendered locally
+}
+
+// RenderedTemplate A template with the values of one recipient filled in
+type RenderedTemplate struct {
+	ObjectName  string
+	Content     TXTMsg
+	PushContent string
+	PushData    string
+}
+
+// TemplateRecipient One recipient of a template send
+type TemplateRecipient struct {
+	UserId string            // Recipient user ID
+	Locale string            // Preferred locale, empty for the default variant
+	Values map[string]string // Placeholder values
+}
+
+// Placeholders Returns the sorted placeholder keys used anywhere in the template.
+func (t MessageTemplate) Placeholders() []string {
+	set := map[string]bool{}
+	for _, s := range []string{t.Content.Content, t.Content.Extra, t.PushContent, t.PushData} {
+		for _, m := range templatePlaceholder.FindAllStringSubmatch(s, -1) {
+			set[m[1]] = true
+		}
+	}
+	return sortedKeys(set)
+}
+
+// Validate Reports the placeholders that have no value.
+/*
+*@param  values: Placeholder values of one recipient.
+*
+*@return error: Lists every missing placeholder, nil when all are present.
+ */
+func (t MessageTemplate) Validate(values map[string]string) error {
+	var missing []string
+	for _, key := range t.Placeholders() {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return RCErrorNew(1002, "Template '"+t.Name+"' is missing values for "+strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Render Fills in the placeholders locally, the same way the server does, for preview and testing.
+/*
+*@param  values: Placeholder values of one recipient.
+*
+*@return RenderedTemplate, error
+ */
+func (t MessageTemplate) Render(values map[string]string) (RenderedTemplate, error) {
+	if err := t.Validate(values); err != nil {
+		return RenderedTemplate{}, err
+	}
+	replace := func(s string) string {
+		return templatePlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+			return values[m[1:len(m)-1]]
+		})
+	}
+	content := t.Content
+	content.Content = replace(content.Content)
+	content.Extra = replace(content.Extra)
+	return RenderedTemplate{
+		ObjectName:  t.objectName(),
+		Content:     content,
+		PushContent: replace(t.PushContent),
+		PushData:    replace(t.PushData),
+	}, nil
+}
+
+func (t MessageTemplate) objectName() string {
+	if t.ObjectName == "" {
+		return "RC:TxtMsg"
+	}
+	return t.ObjectName
+}
+
+// TemplateRegistry Named templates with per-locale variants, safe for concurrent use
+type TemplateRegistry struct {
+	lock      sync.RWMutex
+	templates map[string]map[string]MessageTemplate
+}
+
+// NewTemplateRegistry creates an empty TemplateRegistry
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{templates: map[string]map[string]MessageTemplate{}}
+}
+
+// Register Adds a template, replacing the variant with the same name and locale.
+func (r *TemplateRegistry) Register(t MessageTemplate) error {
+	if t.Name == "" {
+		return RCErrorNew(1002, "Paramer 'name' is required")
+	}
+	if t.Content.Content == "" {
+		return RCErrorNew(1002, "Template '"+t.Name+"' has no content")
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.templates[t.Name] == nil {
+		r.templates[t.Name] = map[string]MessageTemplate{}
+	}
+	r.templates[t.Name][t.Locale] = t
+	return nil
+}
+
+// LoadJSON Registers the templates of a JSON array of MessageTemplate.
+func (r *TemplateRegistry) LoadJSON(reader io.Reader) error {
+	var templates []MessageTemplate
+	if err := json.NewDecoder(reader).Decode(&templates); err != nil {
+		return err
+	}
+	for _, t := range templates {
+		if err := r.Register(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get Returns the variant of a template for a locale, falling back from "zh-CN" to "zh" and then to the default variant.
+func (r *TemplateRegistry) Get(name, locale string) (MessageTemplate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	variants, ok := r.templates[name]
+	if !ok {
+		return MessageTemplate{}, RCErrorNew(1002, "Template '"+name+"' is not registered")
+	}
+	for _, l := range templateLocales(locale) {
+		if t, ok := variants[l]; ok {
+			return t, nil
+		}
+	}
+	return MessageTemplate{}, RCErrorNew(1002, "Template '"+name+"' has no variant for '"+locale+"'")
+}
+
+// templateLocales returns the lookup order for a locale: "zh-CN", "zh", "".
+func templateLocales(locale string) []string {
+	var locales []string
+	for locale != "" {
+		locales = append(locales, locale)
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(locales, "")
+}
+
+// PrivateSendNamedTemplate Sends a registered template as one-to-one messages, see sendNamedTemplate.
+/*
+*@param  registry: Templates.
+*@param  name: Template name.
+*@param  senderID: Sender user ID.
+*@param  recipients: Recipients with their locale and placeholder values.
+*@param  options: Options passed to PrivateSendTemplate.
+*
+*@return []MessageResult: One result per request sent.
+*@return error
+ */
+func (rc *RongCloud) PrivateSendNamedTemplate(registry *TemplateRegistry, name, senderID string,
+	recipients []TemplateRecipient, options ...MsgOption) ([]MessageResult, error) {
+	chunkOptions := idempotencyChunks(options)
+	return rc.sendNamedTemplate(registry, name, recipients, TEMPLATE_PRIVATE_MAX_USERS,
+		func(t MessageTemplate, content []TemplateMsgContent) (MessageResult, error) {
+			return rc.PrivateSendTemplate(senderID, t.objectName(), t.Content, content, chunkOptions()...)
+		})
+}
+
+// SystemSendNamedTemplate Sends a registered template as system messages, see sendNamedTemplate.
+/*
+*@param  registry: Templates.
+*@param  name: Template name.
+*@param  senderID: Sender user ID.
+*@param  recipients: Recipients with their locale and placeholder values.
+*@param  options: Options passed to SystemSendTemplate.
+*
+*@return []MessageResult: One result per request sent.
+*@return error
+ */
+func (rc *RongCloud) SystemSendNamedTemplate(registry *TemplateRegistry, name, senderID string,
+	recipients []TemplateRecipient, options ...MsgOption) ([]MessageResult, error) {
+	chunkOptions := idempotencyChunks(options)
+	return rc.sendNamedTemplate(registry, name, recipients, TEMPLATE_SYSTEM_MAX_USERS,
+		func(t MessageTemplate, content []TemplateMsgContent) (MessageResult, error) {
+			return rc.SystemSendTemplate(senderID, t.objectName(), t.Content, content, chunkOptions()...)
+		})
+}
+
+// sendNamedTemplate resolves the template variant of every recipient and checks that every placeholder has a value
+// before anything is sent. Recipients are then grouped by variant and sent in chunks of at most max users.
+// Content placeholders are left to the server, push content and push data are rendered locally per recipient.
+func (rc *RongCloud) sendNamedTemplate(registry *TemplateRegistry, name string, recipients []TemplateRecipient, max int,
+	send func(MessageTemplate, []TemplateMsgContent) (MessageResult, error)) ([]MessageResult, error) {
+	if len(recipients) == 0 {
+		return nil, RCErrorNew(1002, "Paramer 'recipients' is required")
+	}
+	variants := map[string]MessageTemplate{}
+	contents := map[string][]TemplateMsgContent{}
+	var order []string
+	for _, recipient := range recipients {
+		if recipient.UserId == "" {
+			return nil, RCErrorNew(1002, "Paramer 'userId' is required")
+		}
+		t, err := registry.Get(name, recipient.Locale)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := t.Render(recipient.Values)
+		if err != nil {
+			return nil, RCErrorNew(1002, err.(CodeResult).ErrorMessage+" for user '"+recipient.UserId+"'")
+		}
+		if _, ok := variants[t.Locale]; !ok {
+			variants[t.Locale] = t
+			order = append(order, t.Locale)
+		}
+		contents[t.Locale] = append(contents[t.Locale], TemplateMsgContent{
+			TargetID:    recipient.UserId,
+			Data:        templateData(t, recipient.Values),
+			PushContent: rendered.PushContent,
+			PushData:    rendered.PushData,
+		})
+	}
+	sort.Strings(order)
+
+	var results []MessageResult
+	for _, locale := range order {
+		content := contents[locale]
+		for start := 0; start < len(content); start += max {
+			end := start + max
+			if end > len(content) {
+				end = len(content)
+			}
+			result, err := send(variants[locale], content[start:end])
+			if err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// templateData keeps the values the server needs, with the placeholder braces it expects as keys.
+func templateData(t MessageTemplate, values map[string]string) map[string]string {
+	data := map[string]string{}
+	for _, key := range t.Placeholders() {
+		data["{"+key+"}"] = values[key]
+	}
+	return data
+}
@@ -0,0 +1,99 @@
+package sdk
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTemplates = `[
+	{"name": "score", "content": {"content": "{name}, your score is {score}"}, "pushContent": "{name}, your score is out"},
+	{"name": "score", "locale": "zh", "content": {"content": "{name}，你的成绩是 {score}"}, "pushContent": "{name}，成绩已出"}
+]`
+
+func TestMessageTemplate_Render(t *testing.T) {
+	registry := NewTemplateRegistry()
+	if err := registry.LoadJSON(strings.NewReader(testTemplates)); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, err := registry.Get("score", "zh-CN")
+	if err != nil || tpl.Locale != "zh" {
+		t.Fatalf("unexpected variant: %+v %v", tpl, err)
+	}
+	if tpl, err = registry.Get("score", "en-US"); err != nil || tpl.Locale != "" {
+		t.Fatalf("unexpected variant: %+v %v", tpl, err)
+	}
+	if got := tpl.Placeholders(); !reflect.DeepEqual(got, []string{"name", "score"}) {
+		t.Errorf("unexpected placeholders: %v", got)
+	}
+
+	rendered, err := tpl.Render(map[string]string{"name": "Tom", "score": "90"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered.Content.Content != "Tom, your score is 90" || rendered.PushContent != "Tom, your score is out" {
+		t.Errorf("unexpected rendering: %+v", rendered)
+	}
+	if rendered.ObjectName != "RC:TxtMsg" {
+		t.Errorf("unexpected object name: %s", rendered.ObjectName)
+	}
+
+	if _, err = tpl.Render(map[string]string{"name": "Tom"}); err == nil || !strings.Contains(err.Error(), "score") {
+		t.Errorf("expected missing score, got %v", err)
+	}
+}
+
+func TestSendNamedTemplate(t *testing.T) {
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	registry := NewTemplateRegistry()
+	if err := registry.LoadJSON(strings.NewReader(testTemplates)); err != nil {
+		t.Fatal(err)
+	}
+
+	var recipients []TemplateRecipient
+	for i := 0; i < 5; i++ {
+		recipients = append(recipients, TemplateRecipient{UserId: "u" + string(rune('0'+i)), Values: map[string]string{"name": "n", "score": "1"}})
+	}
+	recipients[4].Locale = "zh"
+
+	var sizes []int
+	send := func(tpl MessageTemplate, content []TemplateMsgContent) (MessageResult, error) {
+		sizes = append(sizes, len(content))
+		if content[0].Data["{name}"] != "n" {
+			t.Errorf("unexpected data: %v", content[0].Data)
+		}
+		return MessageResult{Code: 200}, nil
+	}
+	results, err := rc.sendNamedTemplate(registry, "score", recipients, 2, send)
+	if err != nil || len(results) != 3 || !reflect.DeepEqual(sizes, []int{2, 2, 1}) {
+		t.Errorf("unexpected result: %v %v %v", results, sizes, err)
+	}
+
+	recipients[2].Values = nil
+	sizes = nil
+	if _, err = rc.sendNamedTemplate(registry, "score", recipients, 2, send); err == nil || len(sizes) != 0 {
+		t.Error("expected validation error before sending")
+	}
+}
+
+func TestRongCloud_PrivateSendNamedTemplate(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	registry := NewTemplateRegistry()
+	if err := registry.LoadJSON(strings.NewReader(testTemplates)); err != nil {
+		t.Fatal(err)
+	}
+	results, err := rc.PrivateSendNamedTemplate(registry, "score", "u01", []TemplateRecipient{
+		{UserId: "u02", Values: map[string]string{"name": "Xiao Ming", "score": "90"}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(results)
+}
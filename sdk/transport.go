@@ -0,0 +1,147 @@
+// Retry and rate limit transports, plugged in with WithTransport or SetHttpTransport
+
+package sdk
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryTransport An http.RoundTripper that sends a request again on network errors and on
+// 429, 502, 503 and 504 responses. A request that reached the server may be processed twice,
+// so only use it for calls that are safe to repeat.
+type RetryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	backoff     time.Duration
+	sleep       func(time.Duration)
+}
+
+// NewRetryTransport creates a RetryTransport
+/*
+*@param  next: Transport that sends the requests, nil uses http.DefaultTransport.
+*@param  maxAttempts: Total number of attempts including the first one, values below 1 mean 1.
+*@param  backoff: Wait before the second attempt, doubled before each following attempt.
+*
+*@return *RetryTransport
+ */
+func NewRetryTransport(next http.RoundTripper, maxAttempts int, backoff time.Duration) *RetryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &RetryTransport{next: next, maxAttempts: maxAttempts, backoff: backoff, sleep: time.Sleep}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && t.maxAttempts > 1 {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	wait := t.backoff
+	for attempt := 1; ; attempt++ {
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.maxAttempts || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		t.sleep(wait)
+		wait *= 2
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RateLimitTransport An http.RoundTripper that limits the request rate with a token bucket.
+// Requests wait for a token, or fail with the error of their context when it is done first.
+type RateLimitTransport struct {
+	next     http.RoundTripper
+	interval time.Duration // Time to earn one token
+	burst    int
+	lock     sync.Mutex
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// NewRateLimitTransport creates a RateLimitTransport
+/*
+*@param  next: Transport that sends the requests, nil uses http.DefaultTransport.
+*@param  perSecond: Requests allowed per second, must be greater than 0.
+*@param  burst: Requests that may be sent at once after an idle period, values below 1 mean 1.
+*
+*@return *RateLimitTransport
+ */
+func NewRateLimitTransport(next http.RoundTripper, perSecond float64, burst int) *RateLimitTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitTransport{
+		next:     next,
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    burst,
+		tokens:   float64(burst),
+		now:      time.Now,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := t.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// reserve takes a token and returns how long to wait until it is earned.
+func (t *RateLimitTransport) reserve() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	if !t.last.IsZero() {
+		t.tokens += float64(now.Sub(t.last)) / float64(t.interval)
+		if t.tokens > float64(t.burst) {
+			t.tokens = float64(t.burst)
+		}
+	}
+	t.last = now
+	t.tokens--
+	if t.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-t.tokens * float64(t.interval))
+}
@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// transportFunc adapts a function to http.RoundTripper
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func statusResponse(code int) *http.Response {
+	return &http.Response{StatusCode: code, Body: ioutil.NopCloser(strings.NewReader("{}"))}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var bodies []string
+	results := []func() (*http.Response, error){
+		func() (*http.Response, error) { return nil, errors.New("connection reset") },
+		func() (*http.Response, error) { return statusResponse(http.StatusTooManyRequests), nil },
+		func() (*http.Response, error) { return statusResponse(http.StatusOK), nil },
+	}
+	next := transportFunc(func(req *http.Request) (*http.Response, error) {
+		data, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(data))
+		return results[len(bodies)-1]()
+	})
+	var waits []time.Duration
+	transport := NewRetryTransport(next, 3, 10*time.Millisecond)
+	transport.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/user/getToken.json", strings.NewReader("userId=u01"))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+	if len(bodies) != 3 || bodies[2] != "userId=u01" {
+		t.Errorf("bodies = %q", bodies)
+	}
+	if len(waits) != 2 || waits[0] != 10*time.Millisecond || waits[1] != 20*time.Millisecond {
+		t.Errorf("waits = %v", waits)
+	}
+
+	// The last response is returned when the attempts run out, and a 400 is never retried.
+	bodies = nil
+	results = []func() (*http.Response, error){
+		func() (*http.Response, error) { return statusResponse(http.StatusBadRequest), nil },
+	}
+	req, _ = http.NewRequest(http.MethodPost, "http://example.com/user/getToken.json", strings.NewReader("userId=u01"))
+	if resp, err = transport.RoundTrip(req); err != nil || resp.StatusCode != http.StatusBadRequest || len(bodies) != 1 {
+		t.Errorf("status = %v, err = %v, calls = %d", resp, err, len(bodies))
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	next := transportFunc(func(req *http.Request) (*http.Response, error) {
+		return statusResponse(http.StatusOK), nil
+	})
+	transport := NewRateLimitTransport(next, 10, 2)
+	now := time.Unix(1700000000, 0)
+	transport.now = func() time.Time { return now }
+
+	if wait := transport.reserve(); wait != 0 {
+		t.Errorf("first wait = %v", wait)
+	}
+	if wait := transport.reserve(); wait != 0 {
+		t.Errorf("burst wait = %v", wait)
+	}
+	if wait := transport.reserve(); wait != 100*time.Millisecond {
+		t.Errorf("third wait = %v", wait)
+	}
+	now = now.Add(time.Second)
+	if wait := transport.reserve(); wait != 0 {
+		t.Errorf("wait after refill = %v", wait)
+	}
+
+	// A request whose context ends while waiting fails without reaching next.
+	transport = NewRateLimitTransport(transportFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("request sent")
+		return statusResponse(http.StatusOK), nil
+	}), 0.001, 1)
+	transport.reserve()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if _, err := transport.RoundTrip(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("err = %v", err)
+	}
+}
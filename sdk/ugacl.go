@@ -0,0 +1,427 @@
+// Ultra group channel access built on private channel allowlists, user groups and channel bindings
+
+package sdk
+
+import (
+	"sort"
+)
+
+const (
+	// UG_ACL_PAGE_SIZE Page size of the queries walked by the UGACL functions
+	UG_ACL_PAGE_SIZE = 100
+	// UG_ACL_USER_GROUPS_BATCH Most user groups created, bound or unbound by one request of UGACLApply
+	UG_ACL_USER_GROUPS_BATCH = 10
+	// UG_ACL_USERS_BATCH Most users added to or removed from a user group by one request of UGACLApply
+	UG_ACL_USERS_BATCH = 20
+
+	// UGChannelTypePublic Channel visible to every member of the ultra group
+	UGChannelTypePublic = 0
+	// UGChannelTypePrivate Channel visible only to its allowlist and to the members of bound user groups
+	UGChannelTypePrivate = 1
+)
+
+// UGACL Desired access declaration of an ultra group: channels -> user groups -> users
+type UGACL struct {
+	GroupId    string              `json:"groupId"`    // Ultra group ID
+	Channels   map[string][]string `json:"channels"`   // Private channel ID -> user group IDs bound to it
+	UserGroups map[string][]string `json:"userGroups"` // User group ID -> user IDs in it
+	Prune      bool                `json:"prune"`      // Also removes users of declared user groups that are not declared, which walks all ultra group members
+}
+
+// UGACLViewers Who can see a channel
+type UGACLViewers struct {
+	Public     bool     `json:"public"`     // The channel is public, every member of the ultra group can see it
+	Allowlist  []string `json:"allowlist"`  // Users added directly with UGChannelPrivateUserAdd
+	UserGroups []string `json:"userGroups"` // User groups bound with UGChannelUserGroupBind
+	Users      []string `json:"users"`      // All users that can see the private channel, allowlist and user group members together
+}
+
+// UGACLResult Operations performed by UGACLApply
+type UGACLResult struct {
+	UserGroupsCreated []string            `json:"userGroupsCreated"`
+	UsersAdded        map[string][]string `json:"usersAdded"`   // User group ID -> users added
+	UsersRemoved      map[string][]string `json:"usersRemoved"` // User group ID -> users removed
+	Bound             map[string][]string `json:"bound"`        // Channel ID -> user groups bound
+	Unbound           map[string][]string `json:"unbound"`      // Channel ID -> user groups unbound
+}
+
+// UGACLUserChannels Answers which channels a user can see: all public channels, the private channels
+// whose allowlist contains the user and the private channels bound to one of the user's user groups.
+/*
+*@param  groupId: Ultra group ID.
+*@param  userId: User ID.
+*
+*@return []string: Channel IDs, sorted.
+*@return error
+ */
+func (rc *RongCloud) UGACLUserChannels(groupId, userId string) ([]string, error) {
+	if groupId == "" {
+		return nil, RCErrorNew(1002, "param 'groupId' is required")
+	}
+	if userId == "" {
+		return nil, RCErrorNew(1002, "param 'userId' is required")
+	}
+
+	visible := map[string]bool{}
+	channels, err := rc.ugACLChannels(groupId)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range channels {
+		if c.Type == UGChannelTypePublic {
+			visible[c.ChannelId] = true
+		}
+	}
+
+	direct, err := ugACLWalk(func(page int) ([]string, error) {
+		ids, _, err := rc.UltraGroup().UserChannelQuery(groupId, userId, page, UG_ACL_PAGE_SIZE)
+		return ids, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range direct {
+		visible[c] = true
+	}
+
+	userGroups, err := rc.ugACLUserUserGroups(groupId, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, userGroupId := range userGroups {
+		userGroupId := userGroupId
+		bound, err := ugACLWalk(func(page int) ([]string, error) {
+			ids, _, err := rc.UltraGroup().UserGroupChannelQuery(groupId, userGroupId, page, UG_ACL_PAGE_SIZE)
+			return ids, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range bound {
+			visible[c] = true
+		}
+	}
+
+	return sortedKeys(visible), nil
+}
+
+// UGACLChannelViewers Answers who can see a channel. For a private channel with bound user groups the members of
+// those user groups are found by walking the ultra group members, which costs one request per member.
+/*
+*@param  groupId: Ultra group ID.
+*@param  busChannel: Channel ID.
+*
+*@return UGACLViewers, error
+ */
+func (rc *RongCloud) UGACLChannelViewers(groupId, busChannel string) (UGACLViewers, error) {
+	viewers := UGACLViewers{}
+	if groupId == "" {
+		return viewers, RCErrorNew(1002, "param 'groupId' is required")
+	}
+	if busChannel == "" {
+		return viewers, RCErrorNew(1002, "param 'busChannel' is required")
+	}
+
+	channels, err := rc.ugACLChannels(groupId)
+	if err != nil {
+		return viewers, err
+	}
+	for _, c := range channels {
+		if c.ChannelId == busChannel && c.Type == UGChannelTypePublic {
+			viewers.Public = true
+			return viewers, nil
+		}
+	}
+
+	viewers.Allowlist, err = ugACLWalk(func(page int) ([]string, error) {
+		ids, _, err := rc.UltraGroup().PrivateUsersQuery(groupId, busChannel, page, UG_ACL_PAGE_SIZE)
+		return ids, err
+	})
+	if err != nil {
+		return viewers, err
+	}
+	viewers.UserGroups, err = rc.ugACLChannelUserGroups(groupId, busChannel)
+	if err != nil {
+		return viewers, err
+	}
+
+	users := tagSet(viewers.Allowlist)
+	if len(viewers.UserGroups) > 0 {
+		bound := tagSet(viewers.UserGroups)
+		memberships, err := rc.ugACLMemberships(groupId)
+		if err != nil {
+			return viewers, err
+		}
+		for userId, userGroups := range memberships {
+			for _, userGroupId := range userGroups {
+				if bound[userGroupId] {
+					users[userId] = true
+					break
+				}
+			}
+		}
+	}
+	viewers.Users = sortedKeys(users)
+	return viewers, nil
+}
+
+// UGACLApply Brings the ultra group in line with the declaration: missing user groups are created, declared users are
+// added to their user groups, and the user groups bound to each declared channel are made to match exactly.
+// Channels and user groups that are not declared are left untouched, so running it again performs no operations.
+// Large lists are split into requests of UG_ACL_USER_GROUPS_BATCH user groups or UG_ACL_USERS_BATCH users.
+/*
+*@param  acl: The desired declaration.
+*
+*@return UGACLResult: The operations performed, also when an error stops the run.
+*@return error
+ */
+func (rc *RongCloud) UGACLApply(acl UGACL) (UGACLResult, error) {
+	result := UGACLResult{
+		UsersAdded:   map[string][]string{},
+		UsersRemoved: map[string][]string{},
+		Bound:        map[string][]string{},
+		Unbound:      map[string][]string{},
+	}
+	groupId := acl.GroupId
+	if groupId == "" {
+		return result, RCErrorNew(1002, "param 'groupId' is required")
+	}
+
+	// Every user group referenced by a channel must exist before it can be bound.
+	declared := map[string]bool{}
+	for userGroupId := range acl.UserGroups {
+		declared[userGroupId] = true
+	}
+	for _, userGroups := range acl.Channels {
+		for _, userGroupId := range userGroups {
+			declared[userGroupId] = true
+		}
+	}
+	existing, err := ugACLWalk(func(page int) ([]string, error) {
+		ids, _, err := rc.UltraGroup().UserGroupQuery(groupId, page, UG_ACL_PAGE_SIZE)
+		return ids, err
+	})
+	if err != nil {
+		return result, err
+	}
+	if create := ugACLMissing(sortedKeys(declared), existing); len(create) > 0 {
+		err := ugACLChunks(create, UG_ACL_USER_GROUPS_BATCH, func(chunk []string) error {
+			if _, err := rc.UltraGroup().UserGroupCreate(groupId, chunk...); err != nil {
+				return err
+			}
+			result.UserGroupsCreated = append(result.UserGroupsCreated, chunk...)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// User group members
+	var memberships map[string][]string
+	if acl.Prune {
+		if memberships, err = rc.ugACLMemberships(groupId); err != nil {
+			return result, err
+		}
+	} else {
+		memberships = map[string][]string{}
+		for _, users := range acl.UserGroups {
+			for _, userId := range users {
+				if _, ok := memberships[userId]; ok {
+					continue
+				}
+				if memberships[userId], err = rc.ugACLUserUserGroups(groupId, userId); err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+	current := ugACLInvert(memberships)
+	for _, userGroupId := range sortedMapKeys(acl.UserGroups) {
+		want := acl.UserGroups[userGroupId]
+		if add := ugACLMissing(want, current[userGroupId]); len(add) > 0 {
+			err := ugACLChunks(add, UG_ACL_USERS_BATCH, func(chunk []string) error {
+				if _, err := rc.UltraGroup().UserGroupUsersAdd(groupId, userGroupId, chunk...); err != nil {
+					return err
+				}
+				result.UsersAdded[userGroupId] = append(result.UsersAdded[userGroupId], chunk...)
+				return nil
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+		if !acl.Prune {
+			continue
+		}
+		if remove := ugACLMissing(current[userGroupId], want); len(remove) > 0 {
+			err := ugACLChunks(remove, UG_ACL_USERS_BATCH, func(chunk []string) error {
+				if _, err := rc.UltraGroup().UserGroupUsersRemove(groupId, userGroupId, chunk...); err != nil {
+					return err
+				}
+				result.UsersRemoved[userGroupId] = append(result.UsersRemoved[userGroupId], chunk...)
+				return nil
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// Channel bindings
+	for _, busChannel := range sortedMapKeys(acl.Channels) {
+		want := acl.Channels[busChannel]
+		bound, err := rc.ugACLChannelUserGroups(groupId, busChannel)
+		if err != nil {
+			return result, err
+		}
+		if bind := ugACLMissing(want, bound); len(bind) > 0 {
+			err := ugACLChunks(bind, UG_ACL_USER_GROUPS_BATCH, func(chunk []string) error {
+				if _, err := rc.UltraGroup().ChannelUserGroupBind(groupId, busChannel, chunk...); err != nil {
+					return err
+				}
+				result.Bound[busChannel] = append(result.Bound[busChannel], chunk...)
+				return nil
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+		if unbind := ugACLMissing(bound, want); len(unbind) > 0 {
+			err := ugACLChunks(unbind, UG_ACL_USER_GROUPS_BATCH, func(chunk []string) error {
+				if _, err := rc.UltraGroup().ChannelUserGroupUnbind(groupId, busChannel, chunk...); err != nil {
+					return err
+				}
+				result.Unbound[busChannel] = append(result.Unbound[busChannel], chunk...)
+				return nil
+			})
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (rc *RongCloud) ugACLChannels(groupId string) ([]UltraGroupChannelGetResponseItem, error) {
+	var channels []UltraGroupChannelGetResponseItem
+	for page := 1; ; page++ {
+		items, _, err := rc.UltraGroup().ChannelQuery(groupId, page, UG_ACL_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, items...)
+		if len(items) < UG_ACL_PAGE_SIZE {
+			return channels, nil
+		}
+	}
+}
+
+func (rc *RongCloud) ugACLUserUserGroups(groupId, userId string) ([]string, error) {
+	return ugACLWalk(func(page int) ([]string, error) {
+		ids, _, err := rc.UltraGroup().UserUserGroupQuery(groupId, userId, page, UG_ACL_PAGE_SIZE)
+		return ids, err
+	})
+}
+
+func (rc *RongCloud) ugACLChannelUserGroups(groupId, busChannel string) ([]string, error) {
+	return ugACLWalk(func(page int) ([]string, error) {
+		ids, _, err := rc.UltraGroup().ChannelUserGroupQuery(groupId, busChannel, page, UG_ACL_PAGE_SIZE)
+		return ids, err
+	})
+}
+
+// ugACLMemberships walks all ultra group members and returns user ID -> user group IDs.
+func (rc *RongCloud) ugACLMemberships(groupId string) (map[string][]string, error) {
+	members, err := ugACLWalk(func(page int) ([]string, error) {
+		users, _, err := rc.UltraGroup().Members(groupId, page, UG_ACL_PAGE_SIZE)
+		ids := make([]string, 0, len(users))
+		for _, u := range users {
+			ids = append(ids, u.Id)
+		}
+		return ids, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	memberships := make(map[string][]string, len(members))
+	for _, userId := range members {
+		if memberships[userId], err = rc.ugACLUserUserGroups(groupId, userId); err != nil {
+			return nil, err
+		}
+	}
+	return memberships, nil
+}
+
+// ugACLWalk calls fetch with page 1, 2, ... until a page holds fewer than UG_ACL_PAGE_SIZE items. Fetch must request pages of UG_ACL_PAGE_SIZE.
+func ugACLWalk(fetch func(page int) ([]string, error)) ([]string, error) {
+	var all []string
+	for page := 1; ; page++ {
+		items, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < UG_ACL_PAGE_SIZE {
+			return all, nil
+		}
+	}
+}
+
+// ugACLChunks calls fn with consecutive chunks of at most size items, stopping at the first error.
+func ugACLChunks(items []string, size int, fn func(chunk []string) error) error {
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		if err := fn(items[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ugACLInvert turns user ID -> user group IDs into user group ID -> user IDs.
+func ugACLInvert(memberships map[string][]string) map[string][]string {
+	inverted := map[string][]string{}
+	for userId, userGroups := range memberships {
+		for _, userGroupId := range userGroups {
+			inverted[userGroupId] = append(inverted[userGroupId], userId)
+		}
+	}
+	return inverted
+}
+
+// ugACLMissing returns the sorted items of want that are not in have.
+func ugACLMissing(want, have []string) []string {
+	set := tagSet(have)
+	var missing []string
+	for _, item := range want {
+		if !set[item] {
+			missing = append(missing, item)
+			set[item] = true
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func sortedMapKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
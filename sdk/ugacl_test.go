@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUgACLMissing(t *testing.T) {
+	missing := ugACLMissing([]string{"c", "a", "b", "a"}, []string{"b"})
+	if !reflect.DeepEqual(missing, []string{"a", "c"}) {
+		t.Errorf("unexpected result: %v", missing)
+	}
+	if missing = ugACLMissing([]string{"a"}, []string{"a", "b"}); len(missing) != 0 {
+		t.Errorf("unexpected result: %v", missing)
+	}
+}
+
+func TestUgACLInvert(t *testing.T) {
+	inverted := ugACLInvert(map[string][]string{"u1": {"g1", "g2"}, "u2": {"g2"}})
+	if len(inverted["g1"]) != 1 || len(inverted["g2"]) != 2 {
+		t.Errorf("unexpected result: %v", inverted)
+	}
+}
+
+func TestUgACLWalk(t *testing.T) {
+	var pages []int
+	items, err := ugACLWalk(func(page int) ([]string, error) {
+		pages = append(pages, page)
+		n := UG_ACL_PAGE_SIZE
+		if page == 3 {
+			n = 1
+		}
+		res := make([]string, n)
+		for i := range res {
+			res[i] = strconv.Itoa(page) + "-" + strconv.Itoa(i)
+		}
+		return res, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2*UG_ACL_PAGE_SIZE+1 || !reflect.DeepEqual(pages, []int{1, 2, 3}) {
+		t.Errorf("unexpected walk: %d items, pages %v", len(items), pages)
+	}
+}
+
+func TestUGACLApply_Chunks(t *testing.T) {
+	calls := map[string][]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		n := len(strings.Split(r.Form.Get("userIds")+r.Form.Get("userGroupIds"), ","))
+		calls[r.URL.Path] = append(calls[r.URL.Path], n)
+		_, _ = w.Write([]byte(`{"code": 200}`))
+	}))
+	defer server.Close()
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+
+	acl := UGACL{GroupId: "ug01", Channels: map[string][]string{}, UserGroups: map[string][]string{}}
+	var users []string
+	for i := 0; i < 25; i++ {
+		users = append(users, "u"+strconv.Itoa(i))
+	}
+	for i := 0; i < 12; i++ {
+		acl.Channels["c01"] = append(acl.Channels["c01"], "g"+strconv.Itoa(i))
+	}
+	acl.UserGroups["g0"] = users
+	result, err := rc.UGACLApply(acl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls["/ultragroup/usergroup/add.json"]) != 2 || len(result.UserGroupsCreated) != 12 {
+		t.Errorf("create calls = %v, created = %v", calls["/ultragroup/usergroup/add.json"], result.UserGroupsCreated)
+	}
+	if got := calls["/ultragroup/usergroup/user/add.json"]; !reflect.DeepEqual(got, []int{20, 5}) || len(result.UsersAdded["g0"]) != 25 {
+		t.Errorf("user add calls = %v, added = %v", got, result.UsersAdded)
+	}
+	if got := calls["/ultragroup/channel/usergroup/bind.json"]; !reflect.DeepEqual(got, []int{10, 2}) {
+		t.Errorf("bind calls = %v", got)
+	}
+}
+
+func TestRongCloud_UGACLApply(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	res, err := rc.UGACLApply(UGACL{
+		GroupId:    "rongcloud_group01",
+		Channels:   map[string][]string{"channel01": {"usergroup01"}},
+		UserGroups: map[string][]string{"usergroup01": {"u01", "u02"}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(res)
+
+	channels, err := rc.UGACLUserChannels("rongcloud_group01", "u01")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log(channels)
+}
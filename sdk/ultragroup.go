@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/astaxie/beego/httplib"
@@ -76,6 +75,8 @@ type UGMessage struct {
 //
 //	groupId=ug_m_gid_lw_1&page=1&limit=20
 //	response: Returns byte array
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelQuery
 func (rc *RongCloud) UGGroupChannelGet(groupId string, page, limit int) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -136,6 +137,8 @@ type UGHisMsgIdQueryData struct {
 // param: msgUID Required string The message ID to query, retrieves 10 messages before and after the specified message ID
 // param: prevNum Optional string Default is 10 (maximum 50) messages to retrieve before the message ID. If 0 is passed, no messages before the message ID are retrieved
 // param: lastNum Optional string Default is 10 (maximum 50) messages to retrieve after the message ID. If 0 is passed, no messages after the message ID are retrieved
+//
+// Deprecated: use RongCloud.UltraGroup().HistoryAround
 func (rc *RongCloud) UGHisMsgIdQuery(groupId, busChannel, msgUID, prevNum, lastNum string) (UGHisMsgIdQueryResp, error) {
 	var (
 		result = UGHisMsgIdQueryResp{}
@@ -174,6 +177,8 @@ func (rc *RongCloud) UGHisMsgIdQuery(groupId, busChannel, msgUID, prevNum, lastN
 // param: fromUserId Optional String Sender ID of the message. If provided, only messages sent by this user will be queried; otherwise, all historical messages in the group will be queried.
 // param: pageSize Optional int Default is 20, maximum is 100.
 // UGHisMsgQueryResp: Returned data result set startTime<resultList<=endTime, and resultList data is sorted in ascending order by message timestamp.
+//
+// Deprecated: use RongCloud.UltraGroup().History
 func (rc *RongCloud) UGHistoryQuery(groupId, busChannel string, startTime, endTime int64, fromUserId string, pageSize int) (UGHisMsgQueryResp, error) {
 	var (
 		size   int
@@ -220,6 +225,8 @@ type UGChannelPrivateUserGetObj struct {
 //
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&page=1&pageSize=1000
 //	response: UGChannelPrivateUserGetObj
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersQuery
 func (rc *RongCloud) UGChannelPrivateUserGetResObj(groupId, busChannel, page, pageSize string) (UGChannelPrivateUserGetObj, error) {
 	var (
 		result = UGChannelPrivateUserGetObj{}
@@ -255,6 +262,8 @@ func (rc *RongCloud) UGChannelPrivateUserGetResObj(groupId, busChannel, page, pa
 //
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&page=1&pageSize=1000
 //	response: byte array
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersQuery
 func (rc *RongCloud) UGChannelPrivateUserGet(groupId, busChannel, page, pageSize string) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -284,6 +293,8 @@ type UGChannelPrivateUserDelObj struct {
 //
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&userIds=a%2Cb%2Cc
 //	response: UGChannelPrivateUserDelObj
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersRemove
 func (rc *RongCloud) UGChannelPrivateUserDelResObj(groupId, busChannel, userIds string) (UGChannelPrivateUserDelObj, error) {
 	var (
 		result = UGChannelPrivateUserDelObj{}
@@ -321,6 +332,8 @@ func (rc *RongCloud) UGChannelPrivateUserDelResObj(groupId, busChannel, userIds
 //
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&userIds=a%2Cb%2Cc
 //	response : byte array
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersRemove
 func (rc *RongCloud) UGChannelPrivateUserDel(groupId, busChannel, userIds string) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -352,6 +365,8 @@ type UGChannelPrivateUserAddObj struct {
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&userIds=a%2Cb%2Cc
 //
 // response: UGChannelPrivateUserAddObj
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersAdd
 func (rc *RongCloud) UGChannelPrivateUserAddResObj(groupId, busChannel, userIds string) (UGChannelPrivateUserAddObj, error) {
 	var (
 		result = UGChannelPrivateUserAddObj{}
@@ -388,6 +403,8 @@ func (rc *RongCloud) UGChannelPrivateUserAddResObj(groupId, busChannel, userIds
 // UGChannelPrivateUserAdd: Add users to the private channel allowlist   /ultragroup/channel/private/users/add.json
 //
 //	groupId=ug_m_gid_lw_1&busChannel=channel001&userIds=a%2Cb%2Cc
+//
+// Deprecated: use RongCloud.UltraGroup().PrivateUsersAdd
 func (rc *RongCloud) UGChannelPrivateUserAdd(groupId, busChannel, userIds string) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -417,6 +434,8 @@ func (rc *RongCloud) UGChannelPrivateUserAdd(groupId, busChannel, userIds string
 //
 // response: byte array
 // *//
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelCreate
 func (rc *RongCloud) UGGroupChannelCreate(groupId, busChannel, t string) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -452,6 +471,8 @@ type UGGroupChannelChangeObj struct {
 //	@param: type
 //
 // *//
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelTypeChange
 func (rc *RongCloud) UGGroupChannelChangeResObj(groupId, busChannel, t string) (UGGroupChannelChangeObj, error) {
 	var (
 		result = UGGroupChannelChangeObj{}
@@ -493,6 +514,8 @@ func (rc *RongCloud) UGGroupChannelChangeResObj(groupId, busChannel, t string) (
 //	@param: type
 //
 // *//
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelTypeChange
 func (rc *RongCloud) UGGroupChannelChange(groupId, busChannel, t string) ([]byte, error) {
 	if len(groupId) == 0 {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -516,7 +539,9 @@ func (rc *RongCloud) UGGroupChannelChange(groupId, busChannel, t string) ([]byte
 	return rc.do(req)
 }
 
-// Create a group
+// UGGroupCreate Creates an ultra group /v2/ultragroups
+//
+// Deprecated: use RongCloud.UltraGroup().Create, which calls the v1 API
 func (rc *RongCloud) UGGroupCreate(userId, groupId, groupName string) (err error, requestId string) {
 	if userId == "" {
 		return RCErrorNewV2(1002, "param 'userId' is required"), ""
@@ -551,7 +576,9 @@ func (rc *RongCloud) UGGroupCreate(userId, groupId, groupName string) (err error
 	return err, requestId
 }
 
-// Dismiss an ultra group
+// UGGroupDismiss Dismisses an ultra group /v2/ultragroups/{groupId}
+//
+// Deprecated: use RongCloud.UltraGroup().Dismiss, which calls the v1 API
 func (rc *RongCloud) UGGroupDismiss(groupId string) (err error, requestId string) {
 
 	if groupId == "" {
@@ -569,7 +596,9 @@ func (rc *RongCloud) UGGroupDismiss(groupId string) (err error, requestId string
 	return err, requestId
 }
 
-// Join an ultra group
+// UGGroupJoin Joins an ultra group /v2/ultragroups/{groupId}/users/{userId}
+//
+// Deprecated: use RongCloud.UltraGroup().Join, which calls the v1 API
 func (rc *RongCloud) UGGroupJoin(userId, groupId string) (err error, requestId string) {
 	if userId == "" {
 		return RCErrorNewV2(1002, "param 'userId' is required"), ""
@@ -590,7 +619,9 @@ func (rc *RongCloud) UGGroupJoin(userId, groupId string) (err error, requestId s
 	return err, requestId
 }
 
-// Quit an ultra group
+// UGGroupQuit Quits an ultra group /v2/ultragroups/{groupId}/users/{userId}
+//
+// Deprecated: use RongCloud.UltraGroup().Quit, which calls the v1 API
 func (rc *RongCloud) UGGroupQuit(userId, groupId string) (err error, requestId string) {
 	if userId == "" {
 		return RCErrorNewV2(1002, "param 'userId' is required"), ""
@@ -611,7 +642,9 @@ func (rc *RongCloud) UGGroupQuit(userId, groupId string) (err error, requestId s
 	return err, requestId
 }
 
-// Refresh group information
+// UGGroupUpdate Refreshes ultra group information /v2/ultragroups/{groupId}
+//
+// Deprecated: use RongCloud.UltraGroup().Refresh, which calls the v1 API
 func (rc *RongCloud) UGGroupUpdate(groupId, groupName string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -640,7 +673,9 @@ func (rc *RongCloud) UGGroupUpdate(groupId, groupName string) (err error, reques
 	return err, requestId
 }
 
-// Query groups that a user belongs to (P1)
+// UGQueryUserGroups Queries the groups a user belongs to (P1) /v2/ultragroups/users/{userId}/groups
+//
+// Deprecated: use RongCloud.UltraGroup().GroupsOfUser, which calls the v1 API
 func (rc *RongCloud) UGQueryUserGroups(userId string, page, size int) (groups []UGGroupInfo, err error, requestId string) {
 	if userId == "" {
 		return nil, RCErrorNewV2(1002, "param 'userId' is required"), ""
@@ -681,7 +716,9 @@ func (rc *RongCloud) UGQueryUserGroups(userId string, page, size int) (groups []
 	return groups, err, requestId
 }
 
-// Query group members (P1)
+// UGQueryGroupUsers Queries group members (P1) /v2/ultragroups/{groupId}/users
+//
+// Deprecated: use RongCloud.UltraGroup().Members, which calls the v1 API
 func (rc *RongCloud) UGQueryGroupUsers(groupId string, page, size int) (users []UGUserInfo, err error, requestId string) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -721,7 +758,9 @@ func (rc *RongCloud) UGQueryGroupUsers(groupId string, page, size int) (users []
 	return users, err, requestId
 }
 
-// Send a message in an ultra group
+// UGGroupSend Sends a message in an ultra group /v2/message/ultragroup/send
+//
+// Deprecated: use RongCloud.UltraGroup().Send, which calls the v1 API
 func (rc *RongCloud) UGGroupSend(msg UGMessage) (err error, requestId string) {
 	if msg.FromUserId == "" {
 		return RCErrorNewV2(1002, "Paramer 'FromUserId' is required"), ""
@@ -748,7 +787,9 @@ func (rc *RongCloud) UGGroupSend(msg UGMessage) (err error, requestId string) {
 	return err, requestId
 }
 
-// Add muted members to the group
+// UGGroupMuteMembersAdd Adds muted members to the group /v2/ultragroups/{groupId}/muted-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersAdd, which calls the v1 API with up to 20 users
 func (rc *RongCloud) UGGroupMuteMembersAdd(groupId string, userIds []string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -778,7 +819,9 @@ func (rc *RongCloud) UGGroupMuteMembersAdd(groupId string, userIds []string) (er
 	return err, requestId
 }
 
-// Remove muted members from the group
+// UGGroupMuteMembersRemove Removes muted members from the group /v2/ultragroups/{groupId}/muted-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersRemove, which calls the v1 API with up to 20 users
 func (rc *RongCloud) UGGroupMuteMembersRemove(groupId string, userIds []string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -808,7 +851,9 @@ func (rc *RongCloud) UGGroupMuteMembersRemove(groupId string, userIds []string)
 	return err, requestId
 }
 
-// Get muted members of the ultra group
+// UGGroupMuteMembersGetList Gets the muted members of the ultra group /v2/ultragroups/{groupId}/muted-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersQuery, which calls the v1 API page by page
 func (rc *RongCloud) UGGroupMuteMembersGetList(groupId string) (users []UGUserInfo, err error, requestId string) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -846,7 +891,9 @@ func (rc *RongCloud) UGGroupMuteMembersGetList(groupId string) (users []UGUserIn
 	return users, err, requestId
 }
 
-// Mute all members of the ultra group
+// UGGroupMuted Mutes or unmutes all members of the ultra group /v2/ultragroups/{groupId}/muted-status
+//
+// Deprecated: use RongCloud.UltraGroup().MuteAllSet, which calls the v1 API
 func (rc *RongCloud) UGGroupMuted(groupId string, status bool) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -872,7 +919,9 @@ func (rc *RongCloud) UGGroupMuted(groupId string, status bool) (err error, reque
 	return err, requestId
 }
 
-// Query the mute status of all members in an ultra group
+// UGGroupMutedQuery Queries the mute status of all members in an ultra group /v2/ultragroups/{groupId}/muted-status
+//
+// Deprecated: use RongCloud.UltraGroup().MuteAllQuery, which calls the v1 API
 func (rc *RongCloud) UGGroupMutedQuery(groupId string) (status bool, err error, requestId string) {
 	if groupId == "" {
 		return status, RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -904,7 +953,9 @@ func (rc *RongCloud) UGGroupMutedQuery(groupId string) (status bool, err error,
 	return status, err, requestId
 }
 
-// Add users to the mute exceptions list
+// UGGroupMutedWhitelistAdd Adds users to the mute exceptions list /v2/ultragroups/{groupId}/allowed-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistAdd, which calls the v1 API with up to 20 users
 func (rc *RongCloud) UGGroupMutedWhitelistAdd(groupId string, userIds []string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -934,7 +985,9 @@ func (rc *RongCloud) UGGroupMutedWhitelistAdd(groupId string, userIds []string)
 	return err, requestId
 }
 
-// Remove users from the mute exceptions list
+// UGGroupMutedWhitelistRemove Removes users from the mute exceptions list /v2/ultragroups/{groupId}/allowed-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistRemove, which calls the v1 API with up to 20 users
 func (rc *RongCloud) UGGroupMutedWhitelistRemove(groupId string, userIds []string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "Paramer 'groupId' is required"), ""
@@ -964,7 +1017,9 @@ func (rc *RongCloud) UGGroupMutedWhitelistRemove(groupId string, userIds []strin
 	return err, requestId
 }
 
-// Query the allowlist for muted users in an ultra group
+// UGGroupMutedWhitelistQuery Queries the mute exceptions list of an ultra group /v2/ultragroups/{groupId}/allowed-users
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistQuery, which calls the v1 API page by page
 func (rc *RongCloud) UGGroupMutedWhitelistQuery(groupId string) (users []UGUserInfo, err error, requestId string) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -1001,7 +1056,9 @@ func (rc *RongCloud) UGGroupMutedWhitelistQuery(groupId string) (users []UGUserI
 	return users, err, requestId
 }
 
-// Create a group channel
+// UGChannelCreate Creates a group channel /v2/ultragroups/channels
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelCreate, which calls the v1 API
 func (rc *RongCloud) UGChannelCreate(groupId, channelId string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -1032,7 +1089,9 @@ func (rc *RongCloud) UGChannelCreate(groupId, channelId string) (err error, requ
 	return nil, requestId
 }
 
-// Delete a group channel
+// UGChannelDelete Deletes a group channel /v2/ultragroups/{groupId}/channels/{channelId}
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelDelete, which calls the v1 API
 func (rc *RongCloud) UGChannelDelete(groupId, channelId string) (err error, requestId string) {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -1053,7 +1112,9 @@ func (rc *RongCloud) UGChannelDelete(groupId, channelId string) (err error, requ
 	return err, requestId
 }
 
-// Query group channel list
+// UGChannelQuery Queries the group channel list /v2/ultragroups/{groupId}/channels
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelQuery, which calls the v1 API
 func (rc *RongCloud) UGChannelQuery(groupId string, page, size int) (channels []UGChannelInfo, err error, requestId string) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required"), ""
@@ -1094,7 +1155,9 @@ func (rc *RongCloud) UGChannelQuery(groupId string, page, size int) (channels []
 	return channels, err, requestId
 }
 
-// UGMessageExpansionSet Set message extension
+// UGMessageExpansionSet Sets message expansion
+//
+// Deprecated: use RongCloud.UltraGroup().ExpansionSet, which returns CodeResult errors
 func (rc *RongCloud) UGMessageExpansionSet(groupId, userId, msgUID, busChannel string, extra map[string]string) error {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1142,6 +1205,8 @@ func (rc *RongCloud) UGMessageExpansionSet(groupId, userId, msgUID, busChannel s
 }
 
 // UGMessageExpansionDelete Deletes message expansion
+//
+// Deprecated: use RongCloud.UltraGroup().ExpansionDelete, which returns CodeResult errors
 func (rc *RongCloud) UGMessageExpansionDelete(groupId, userId, msgUID, busChannel string, keys ...string) error {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1190,7 +1255,9 @@ type UGMessageExpansionItem struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
-// UGMessageExpansionQuery Query message expansion information
+// UGMessageExpansionQuery Queries message expansion information
+//
+// Deprecated: use RongCloud.UltraGroup().ExpansionQuery, which returns CodeResult errors
 func (rc *RongCloud) UGMessageExpansionQuery(groupId, msgUID, busChannel string) ([]UGMessageExpansionItem, error) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1258,6 +1325,8 @@ type PushExt struct {
 
 // UGMessagePublish Sends a message to an ultra group
 // Documentation: https://doc.rongcloud.cn/imserver/server/v1/message/msgsend/ultragroup
+//
+// Deprecated: use RongCloud.UltraGroup().Publish, which returns CodeResult errors
 func (rc *RongCloud) UGMessagePublish(fromUserId, objectName, content, pushContent, pushData, isPersisted,
 	isCounted, isMentioned, contentAvailable, busChannel, extraContent string, expansion,
 	unreadCountFlag bool, pushExt *PushExt, toGroupIds ...string) (MessageResult, error) {
@@ -1355,6 +1424,8 @@ func (rc *RongCloud) UGMessagePublish(fromUserId, objectName, content, pushConte
 }
 
 // UGMemberExists Checks if a user exists in an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MemberExists, which returns CodeResult errors
 func (rc *RongCloud) UGMemberExists(groupId, userId string) (bool, error) {
 	if groupId == "" {
 		return false, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1393,6 +1464,8 @@ func (rc *RongCloud) UGMemberExists(groupId, userId string) (bool, error) {
 }
 
 // UGNotDisturbSet Sets the default Do Not Disturb level for a group/channel
+//
+// Deprecated: use RongCloud.UltraGroup().NotDisturbSet, which returns CodeResult errors
 func (rc *RongCloud) UGNotDisturbSet(groupId string, unPushLevel int, busChannel string) error {
 	if groupId == "" {
 		return RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1443,6 +1516,9 @@ type UGNotDisturbGetResponses struct {
 	UnPushLevel int    `json:"unpushLevel"`
 }
 
+// UGNotDisturbGet Queries the default Do Not Disturb level for a group/channel
+//
+// Deprecated: use RongCloud.UltraGroup().NotDisturbQuery
 func (rc *RongCloud) UGNotDisturbGet(groupId, busChannel string) (*UGNotDisturbGetResponses, error) {
 	if groupId == "" {
 		return nil, RCErrorNewV2(1002, "param 'groupId' is required")
@@ -1489,1038 +1565,256 @@ func (rc *RongCloud) UGNotDisturbGet(groupId, busChannel string) (*UGNotDisturbG
 }
 
 // UltraGroupCreate Creates an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().Create
 func (rc *RongCloud) UltraGroupCreate(userId, groupId, groupName string) error {
-	if userId == "" {
-		return RCErrorNew(1002, "param 'userId' is empty")
-	}
-
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if groupName == "" {
-		return RCErrorNew(1002, "param 'groupName' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/create.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("userId", userId)
-	req.Param("groupId", groupId)
-	req.Param("groupName", groupName)
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
+	_, err := rc.UltraGroup().Create(userId, groupId, groupName)
+	return err
 }
 
 // UltraGroupDis Dissolve an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().Dismiss
 func (rc *RongCloud) UltraGroupDis(groupId string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/dis.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
+	_, err := rc.UltraGroup().Dismiss(groupId)
+	return err
 }
 
 // UltraGroupJoin Join an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().Join
 func (rc *RongCloud) UltraGroupJoin(userId, groupId string) error {
-	if userId == "" {
-		return RCErrorNew(1002, "param 'userId' is empty")
-	}
-
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/join.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("userId", userId)
-	req.Param("groupId", groupId)
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
+	_, err := rc.UltraGroup().Join(userId, groupId)
+	return err
 }
 
 // UltraGroupQuit Quit an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().Quit
 func (rc *RongCloud) UltraGroupQuit(userId, groupId string) error {
-	if userId == "" {
-		return RCErrorNew(1002, "param 'userId' is empty")
-	}
+	_, err := rc.UltraGroup().Quit(userId, groupId)
+	return err
+}
 
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
+// UltraGroupRefresh Refreshes ultra group information
+//
+// Deprecated: use RongCloud.UltraGroup().Refresh
+func (rc *RongCloud) UltraGroupRefresh(groupId, groupName string) error {
+	_, err := rc.UltraGroup().Refresh(groupId, groupName)
+	return err
+}
 
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/quit.json")
+// UltraGroupUserBannedAdd Add banned members to the ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersAdd
+func (rc *RongCloud) UltraGroupUserBannedAdd(groupId, busChannel string, userIds ...string) error {
+	_, err := rc.UltraGroup().MuteMembersAdd(groupId, busChannel, userIds...)
+	return err
+}
 
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
+// UltraGroupUserBannedDel Remove banned users from the ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersRemove
+func (rc *RongCloud) UltraGroupUserBannedDel(groupId, busChannel string, userIds ...string) error {
+	_, err := rc.UltraGroup().MuteMembersRemove(groupId, busChannel, userIds...)
+	return err
+}
 
-	req.Param("userId", userId)
-	req.Param("groupId", groupId)
+type UltraGroupUserBannedResponseItem struct {
+	Id string `json:"id"`
+}
 
-	resp, err := rc.do(req)
+// UltraGroupUserBannedGet Get banned users in an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MuteMembersQuery
+func (rc *RongCloud) UltraGroupUserBannedGet(groupId, busChannel string, page, pageSize int) ([]UltraGroupUserBannedResponseItem, error) {
+	users, _, err := rc.UltraGroup().MuteMembersQuery(groupId, busChannel, page, pageSize)
 	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
+		return nil, err
 	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
+	items := make([]UltraGroupUserBannedResponseItem, 0, len(users))
+	for _, u := range users {
+		items = append(items, UltraGroupUserBannedResponseItem{Id: u.Id})
 	}
-
-	return nil
+	return items, nil
 }
 
-// UltraGroupRefresh Refreshes ultra group information
-func (rc *RongCloud) UltraGroupRefresh(groupId, groupName string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
+// UltraGroupGlobalBannedSet Set the mute status for an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MuteAllSet
+func (rc *RongCloud) UltraGroupGlobalBannedSet(groupId, busChannel string, status bool) error {
+	_, err := rc.UltraGroup().MuteAllSet(groupId, busChannel, status)
+	return err
+}
 
-	if groupName == "" {
-		return RCErrorNew(1002, "param 'groupName' is empty")
-	}
+// UltraGroupGlobalBannedGet Query the mute status of an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().MuteAllQuery
+func (rc *RongCloud) UltraGroupGlobalBannedGet(groupId, busChannel string) (bool, error) {
+	status, _, err := rc.UltraGroup().MuteAllQuery(groupId, busChannel)
+	return status, err
+}
 
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/refresh.json")
+// UltraGroupBannedWhiteListAdd Add users to the mute exceptions list
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistAdd
+func (rc *RongCloud) UltraGroupBannedWhiteListAdd(groupId, busChannel string, userIds ...string) error {
+	_, err := rc.UltraGroup().MuteWhitelistAdd(groupId, busChannel, userIds...)
+	return err
+}
 
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
+// UltraGroupBannedWhiteListDel Remove users from the mute exceptions list
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistRemove
+func (rc *RongCloud) UltraGroupBannedWhiteListDel(groupId, busChannel string, userIds ...string) error {
+	_, err := rc.UltraGroup().MuteWhitelistRemove(groupId, busChannel, userIds...)
+	return err
+}
 
-	req.Param("groupId", groupId)
-	req.Param("groupName", groupName)
+type UltraGroupBannedWhiteListGetResponseItem struct {
+	Id string `json:"id"`
+}
 
-	resp, err := rc.do(req)
+// UltraGroupBannedWhiteListGet Get the Mute Exceptions list
+//
+// Deprecated: use RongCloud.UltraGroup().MuteWhitelistQuery
+func (rc *RongCloud) UltraGroupBannedWhiteListGet(groupId, busChannel string, page, pageSize int) ([]UltraGroupBannedWhiteListGetResponseItem, error) {
+	users, _, err := rc.UltraGroup().MuteWhitelistQuery(groupId, busChannel, page, pageSize)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
+	items := make([]UltraGroupBannedWhiteListGetResponseItem, 0, len(users))
+	for _, u := range users {
+		items = append(items, UltraGroupBannedWhiteListGetResponseItem{Id: u.Id})
 	}
+	return items, nil
+}
 
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
+// UltraGroupChannelCreate Create a public channel
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelCreate
+func (rc *RongCloud) UltraGroupChannelCreate(groupId, busChannel string) error {
+	_, err := rc.UltraGroup().ChannelCreate(groupId, busChannel, UGChannelTypePublic)
+	return err
+}
 
-	return nil
+// UltraGroupChannelDel Deletes a channel
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelDelete
+func (rc *RongCloud) UltraGroupChannelDel(groupId, busChannel string) error {
+	_, err := rc.UltraGroup().ChannelDelete(groupId, busChannel)
+	return err
 }
 
-// UltraGroupUserBannedAdd Add banned members to the ultra group
-func (rc *RongCloud) UltraGroupUserBannedAdd(groupId, busChannel string, userIds ...string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
+type UltraGroupChannelGetResponseItem struct {
+	ChannelId  string `json:"channelId"`
+	Type       int    `json:"type"`
+	CreateTime string `json:"createTime"`
+}
 
-	if userIds == nil || len(userIds) <= 0 {
-		return RCErrorNew(1002, "param 'userIds' is empty")
-	}
+// UltraGroupChannelGet Query the list of channels
+// response：[]UltraGroupChannelGetResponseItem
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelQuery
+func (rc *RongCloud) UltraGroupChannelGet(groupId string, page, limit int) ([]UltraGroupChannelGetResponseItem, error) {
+	channels, _, err := rc.UltraGroup().ChannelQuery(groupId, page, limit)
+	return channels, err
+}
 
-	if len(userIds) > 20 {
-		return RCErrorNew(1002, "param 'userIds' is too long")
+// UGUserGroupAdd Batch create user groups
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupCreate
+func (rc *RongCloud) UGUserGroupAdd(groupId string, userGroups []UGUserGroupInfo) (err error) {
+	ids := make([]string, 0, len(userGroups))
+	for _, g := range userGroups {
+		ids = append(ids, g.UserGroupId)
 	}
+	_, err = rc.UltraGroup().UserGroupCreate(groupId, ids...)
+	return err
+}
 
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/userbanned/add.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
+// UGUserGroupDelete Batch delete user groups
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupDelete
+func (rc *RongCloud) UGUserGroupDelete(groupId string, userGroupIds []string) (err error) {
+	_, err = rc.UltraGroup().UserGroupDelete(groupId, userGroupIds...)
+	return err
+}
 
-	resp, err := rc.do(req)
+// UGUserGroupQuery Paginates and queries user group information under an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupQuery
+func (rc *RongCloud) UGUserGroupQuery(groupId string, page, pageSize int) (userGroups []UGUserGroupInfo, err error) {
+	ids, _, err := rc.UltraGroup().UserGroupQuery(groupId, page, pageSize)
 	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
+		return nil, err
 	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
+	for _, id := range ids {
+		userGroups = append(userGroups, UGUserGroupInfo{UserGroupId: id})
 	}
+	return userGroups, nil
+}
 
-	return nil
+// UGUserGroupUserAdd Adds users to a user group in bulk
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupUsersAdd
+func (rc *RongCloud) UGUserGroupUserAdd(groupId, userGroupId string, userIds []string) (err error) {
+	_, err = rc.UltraGroup().UserGroupUsersAdd(groupId, userGroupId, userIds...)
+	return err
 }
 
-// UltraGroupUserBannedDel Remove banned users from the ultra group
-func (rc *RongCloud) UltraGroupUserBannedDel(groupId, busChannel string, userIds ...string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if userIds == nil || len(userIds) <= 0 {
-		return RCErrorNew(1002, "param 'userIds' is empty")
-	}
-
-	if len(userIds) > 20 {
-		return RCErrorNew(1002, "param 'userIds' is too long")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/userbanned/del.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-type UltraGroupUserBannedResponseItem struct {
-	Id string `json:"id"`
-}
-
-// UltraGroupUserBannedGet Get banned users in an ultra group
-func (rc *RongCloud) UltraGroupUserBannedGet(groupId, busChannel string, page, pageSize int) ([]UltraGroupUserBannedResponseItem, error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/userbanned/get.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	if page != 0 {
-		req.Param("page", strconv.Itoa(page))
-	}
-
-	if pageSize != 0 {
-		req.Param("pageSize", strconv.Itoa(pageSize))
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code  int                                `json:"code"`
-		Users []UltraGroupUserBannedResponseItem `json:"users"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return nil, err
-	}
-
-	if data.Code != 200 {
-		return nil, fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return data.Users, nil
-}
-
-// UltraGroupGlobalBannedSet Set the mute status for an ultra group
-func (rc *RongCloud) UltraGroupGlobalBannedSet(groupId, busChannel string, status bool) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/globalbanned/set.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("status", strconv.FormatBool(status))
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-// UltraGroupGlobalBannedGet Query the mute status of an ultra group
-func (rc *RongCloud) UltraGroupGlobalBannedGet(groupId, busChannel string) (bool, error) {
-	if groupId == "" {
-		return false, RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/globalbanned/get.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return false, err
-	}
-
-	data := struct {
-		Code   int  `json:"code"`
-		Status bool `json:"status"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return false, err
-	}
-
-	if data.Code != 200 {
-		return false, fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return data.Status, nil
-}
-
-// UltraGroupBannedWhiteListAdd Add users to the mute exceptions list
-func (rc *RongCloud) UltraGroupBannedWhiteListAdd(groupId, busChannel string, userIds ...string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if userIds == nil || len(userIds) <= 0 {
-		return RCErrorNew(1002, "param 'userIds' is empty")
-	}
-
-	if len(userIds) > 20 {
-		return RCErrorNew(1002, "param 'userIds' is too long")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/banned/whitelist/add.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-// UltraGroupBannedWhiteListDel Remove users from the mute exceptions list
-func (rc *RongCloud) UltraGroupBannedWhiteListDel(groupId, busChannel string, userIds ...string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if userIds == nil || len(userIds) <= 0 {
-		return RCErrorNew(1002, "param 'userIds' is empty")
-	}
-
-	if len(userIds) > 20 {
-		return RCErrorNew(1002, "param 'userIds' is too long")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/banned/whitelist/del.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-type UltraGroupBannedWhiteListGetResponseItem struct {
-	Id string `json:"id"`
-}
-
-// UltraGroupBannedWhiteListGet Get the Mute Exceptions list
-func (rc *RongCloud) UltraGroupBannedWhiteListGet(groupId, busChannel string, page, pageSize int) ([]UltraGroupBannedWhiteListGetResponseItem, error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/banned/whitelist/get.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-
-	if busChannel != "" {
-		req.Param("busChannel", busChannel)
-	}
-
-	if page != 0 {
-		req.Param("page", strconv.Itoa(page))
-	}
-
-	if pageSize != 0 {
-		req.Param("pageSize", strconv.Itoa(pageSize))
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code  int                                        `json:"code"`
-		Users []UltraGroupBannedWhiteListGetResponseItem `json:"users"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return nil, err
-	}
-
-	if data.Code != 200 {
-		return nil, fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return data.Users, nil
-}
-
-// UltraGroupChannelCreate Create a channel
-func (rc *RongCloud) UltraGroupChannelCreate(groupId, busChannel string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if busChannel == "" {
-		return RCErrorNew(1002, "param 'busChannel' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/channel/create.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("busChannel", busChannel)
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-// UltraGroupChannelDel Deletes a channel
-func (rc *RongCloud) UltraGroupChannelDel(groupId, busChannel string) error {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	if busChannel == "" {
-		return RCErrorNew(1002, "param 'busChannel' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/channel/del.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("busChannel", busChannel)
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return err
-	}
-
-	data := struct {
-		Code int `json:"code"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return err
-	}
-
-	if data.Code != 200 {
-		return fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return nil
-}
-
-type UltraGroupChannelGetResponseItem struct {
-	ChannelId  string `json:"channelId"`
-	Type       int    `json:"type"`
-	CreateTime string `json:"createTime"`
-}
-
-// UltraGroupChannelGet Query the list of channels
-// response：[]UltraGroupChannelGetResponseItem
-func (rc *RongCloud) UltraGroupChannelGet(groupId string, page, limit int) ([]UltraGroupChannelGetResponseItem, error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is empty")
-	}
-
-	req := httplib.Post(rc.rongCloudURI + "/ultragroup/channel/get.json")
-
-	req.SetTimeout(rc.timeout*time.Second, rc.timeout*time.Second)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-
-	if page != 0 {
-		req.Param("page", strconv.Itoa(page))
-	}
-
-	if limit != 0 {
-		req.Param("limit", strconv.Itoa(limit))
-	}
-
-	resp, err := rc.do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code     int                                `json:"code"`
-		Channels []UltraGroupChannelGetResponseItem `json:"channelList"`
-	}{}
-
-	if err = json.Unmarshal(resp, &data); err != nil {
-		return nil, err
-	}
-
-	if data.Code != 200 {
-		return nil, fmt.Errorf("response error. code: %d", data.Code)
-	}
-
-	return data.Channels, nil
-}
-
-// UGUserGroupAdd Batch create user groups
-func (rc *RongCloud) UGUserGroupAdd(groupId string, userGroups []UGUserGroupInfo) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userGroups == nil || len(userGroups) < 1 {
-		return RCErrorNew(1002, "param 'userGroups' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/add.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	body := map[string]interface{}{
-		"groupId":    groupId,
-		"userGroups": userGroups,
-	}
-
-	if _, err = req.JSONBody(body); err != nil {
-		return err
-	}
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// UGUserGroupDelete Batch delete user groups
-func (rc *RongCloud) UGUserGroupDelete(groupId string, userGroupIds []string) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userGroupIds == nil || len(userGroupIds) < 1 {
-		return RCErrorNew(1002, "param 'userGroupIds' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/del.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userGroupIds", strings.Join(userGroupIds, ","))
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// UGUserGroupQuery Paginates and queries user group information under an ultra group
-func (rc *RongCloud) UGUserGroupQuery(groupId string, page, pageSize int) (userGroups []UGUserGroupInfo, err error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/query.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("page", strconv.Itoa(page))
-	req.Param("pageSize", strconv.Itoa(pageSize))
-
-	respBody, err := rc.do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code       int               `json:"code"`
-		UserGroups []UGUserGroupInfo `json:"userGroups"`
-	}{}
-
-	if err := json.Unmarshal(respBody, &data); err != nil {
-		return nil, err
-	}
-
-	return data.UserGroups, nil
-}
-
-// UGUserGroupUserAdd Adds users to a user group in bulk
-func (rc *RongCloud) UGUserGroupUserAdd(groupId, userGroupId string, userIds []string) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userGroupId == "" {
-		return RCErrorNew(1002, "param 'userGroupId' is required")
-	}
-
-	if userIds == nil || len(userIds) < 1 {
-		return RCErrorNew(1002, "param 'userIds' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/user/add.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userGroupId", userGroupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// UGUserGroupUserDelete Batch remove users from a user group
-func (rc *RongCloud) UGUserGroupUserDelete(groupId, userGroupId string, userIds []string) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userGroupId == "" {
-		return RCErrorNew(1002, "param 'userGroupId' is required")
-	}
-
-	if userIds == nil || len(userIds) < 1 {
-		return RCErrorNew(1002, "param 'userIds' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/user/del.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userGroupId", userGroupId)
-	req.Param("userIds", strings.Join(userIds, ","))
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
-}
+// UGUserGroupUserDelete Batch remove users from a user group
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupUsersRemove
+func (rc *RongCloud) UGUserGroupUserDelete(groupId, userGroupId string, userIds []string) (err error) {
+	_, err = rc.UltraGroup().UserGroupUsersRemove(groupId, userGroupId, userIds...)
+	return err
+}
 
 // UGUserUserGroupQuery Query the list of user groups a user belongs to in an ultra group
+//
+// Deprecated: use RongCloud.UltraGroup().UserUserGroupQuery
 func (rc *RongCloud) UGUserUserGroupQuery(groupId, userId string, page, pageSize int) (userGroupIds []string, err error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userId == "" {
-		return nil, RCErrorNew(1002, "param 'userId' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/user/usergroup/query.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userId", userId)
-	req.Param("page", strconv.Itoa(page))
-	req.Param("pageSize", strconv.Itoa(pageSize))
-
-	respBody, err := rc.do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code         int      `json:"code"`
-		UserGroupIds []string `json:"data"`
-	}{}
-
-	if err := json.Unmarshal(respBody, &data); err != nil {
-		return nil, err
-	}
-
-	return data.UserGroupIds, nil
+	userGroupIds, _, err = rc.UltraGroup().UserUserGroupQuery(groupId, userId, page, pageSize)
+	return userGroupIds, err
 }
 
 // UGChannelUserGroupBind Bind user groups to a channel in bulk
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelUserGroupBind
 func (rc *RongCloud) UGChannelUserGroupBind(groupId, busChannel string, userGroupIds []string) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if busChannel == "" {
-		return RCErrorNew(1002, "param 'busChannel' is required")
-	}
-
-	if userGroupIds == nil || len(userGroupIds) < 1 {
-		return RCErrorNew(1002, "param 'userGroupIds' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/channel/usergroup/bind.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("busChannel", busChannel)
-	req.Param("userGroupIds", strings.Join(userGroupIds, ","))
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
+	_, err = rc.UltraGroup().ChannelUserGroupBind(groupId, busChannel, userGroupIds...)
+	return err
 }
 
 // UGChannelUserGroupUnbind Unbind user groups from a channel in bulk
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelUserGroupUnbind
 func (rc *RongCloud) UGChannelUserGroupUnbind(groupId, busChannel string, userGroupIds []string) (err error) {
-	if groupId == "" {
-		return RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if busChannel == "" {
-		return RCErrorNew(1002, "param 'busChannel' is required")
-	}
-
-	if userGroupIds == nil || len(userGroupIds) < 1 {
-		return RCErrorNew(1002, "param 'userGroupIds' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/channel/usergroup/unbind.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("busChannel", busChannel)
-	req.Param("userGroupIds", strings.Join(userGroupIds, ","))
-
-	if _, err = rc.do(req); err != nil {
-		return err
-	}
-
-	return nil
+	_, err = rc.UltraGroup().ChannelUserGroupUnbind(groupId, busChannel, userGroupIds...)
+	return err
 }
 
 // UGChannelUserGroupQuery Query the list of user groups bound to the channel
+//
+// Deprecated: use RongCloud.UltraGroup().ChannelUserGroupQuery
 func (rc *RongCloud) UGChannelUserGroupQuery(groupId, busChannel string, page, pageSize int) (userGroupIds []string, err error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if busChannel == "" {
-		return nil, RCErrorNew(1002, "param 'busChannel' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/channel/usergroup/query.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("busChannel", busChannel)
-	req.Param("page", strconv.Itoa(page))
-	req.Param("pageSize", strconv.Itoa(pageSize))
-
-	respBody, err := rc.do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code         int      `json:"code"`
-		UserGroupIds []string `json:"data"`
-	}{}
-
-	if err := json.Unmarshal(respBody, &data); err != nil {
-		return nil, err
-	}
-
-	return data.UserGroupIds, nil
+	userGroupIds, _, err = rc.UltraGroup().ChannelUserGroupQuery(groupId, busChannel, page, pageSize)
+	return userGroupIds, err
 }
 
 // UGUserGroupChannelQuery Query the list of channels bound to the user group
+//
+// Deprecated: use RongCloud.UltraGroup().UserGroupChannelQuery
 func (rc *RongCloud) UGUserGroupChannelQuery(groupId, userGroupId string, page, pageSize int) (busChannelIds []string, err error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userGroupId == "" {
-		return nil, RCErrorNew(1002, "param 'userGroupId' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/usergroup/channel/query.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userGroupId", userGroupId)
-	req.Param("page", strconv.Itoa(page))
-	req.Param("pageSize", strconv.Itoa(pageSize))
-
-	respBody, err := rc.do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code          int      `json:"code"`
-		BusChannelIds []string `json:"data"`
-	}{}
-
-	if err := json.Unmarshal(respBody, &data); err != nil {
-		return nil, err
-	}
-
-	return data.BusChannelIds, nil
+	busChannelIds, _, err = rc.UltraGroup().UserGroupChannelQuery(groupId, userGroupId, page, pageSize)
+	return busChannelIds, err
 }
 
 // UGUserChannelQuery Query the allowlist of channels the user belongs to
+//
+// Deprecated: use RongCloud.UltraGroup().UserChannelQuery
 func (rc *RongCloud) UGUserChannelQuery(groupId, userId string, page, pageSize int) (busChannelIds []string, err error) {
-	if groupId == "" {
-		return nil, RCErrorNew(1002, "param 'groupId' is required")
-	}
-
-	if userId == "" {
-		return nil, RCErrorNew(1002, "param 'userId' is required")
-	}
-
-	url := fmt.Sprintf("%s/ultragroup/user/channel/query.%s", rc.rongCloudURI, ReqType)
-	req := httplib.Post(url)
-	req.SetTimeout(time.Second*rc.timeout, time.Second*rc.timeout)
-	rc.fillHeader(req)
-
-	req.Param("groupId", groupId)
-	req.Param("userId", userId)
-	req.Param("page", strconv.Itoa(page))
-	req.Param("pageSize", strconv.Itoa(pageSize))
-
-	respBody, err := rc.do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	data := struct {
-		Code          int      `json:"code"`
-		BusChannelIds []string `json:"data"`
-	}{}
-
-	if err := json.Unmarshal(respBody, &data); err != nil {
-		return nil, err
-	}
-
-	return data.BusChannelIds, nil
+	busChannelIds, _, err = rc.UltraGroup().UserChannelQuery(groupId, userId, page, pageSize)
+	return busChannelIds, err
 }
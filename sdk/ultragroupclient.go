@@ -0,0 +1,662 @@
+// Ultra group client
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/httplib"
+)
+
+// UltraGroupClient Ultra group API with one typed method per operation, obtained with RongCloud.UltraGroup.
+// Every method returns the X-Request-Id of the request it sent, empty when the parameters were rejected
+// locally, and reports server errors as CodeResult whichever API version the endpoint belongs to.
+type UltraGroupClient struct {
+	rc *RongCloud
+}
+
+// UGPublishMessage Parameters of UltraGroupClient.Publish
+type UGPublishMessage struct {
+	FromUserId       string   // Sender user ID (required)
+	ToGroupIds       []string // Target ultra group IDs, 1 to 3 (required)
+	ObjectName       string   // Message type (required)
+	Content          string   // Message content (required)
+	PushContent      string   // Push notification content
+	PushData         string   // Additional push data
+	IsPersisted      string   // "1" stores the message on the server, "0" does not
+	IsCounted        string   // "1" counts the message as unread, "0" does not. Defaults to "1"
+	IsMentioned      string   // "1" marks the message as an @ message
+	ContentAvailable string   // "1" enables iOS silent push
+	BusChannel       string   // Channel ID
+	ExtraContent     string   // Initial message expansion, JSON encoded
+	Expansion        bool     // Whether the message can be expanded
+	UnreadCountFlag  bool     // Whether an @ message also counts as unread, ignored for @ messages
+	PushExt          *PushExt // Push extension
+}
+
+// UltraGroup returns the ultra group client
+func (rc *RongCloud) UltraGroup() *UltraGroupClient {
+	return &UltraGroupClient{rc: rc}
+}
+
+// Create Creates an ultra group /ultragroup/create.json
+func (c *UltraGroupClient) Create(userId, groupId, groupName string) (string, error) {
+	if err := ugRequire("userId", userId, "groupId", groupId, "groupName", groupName); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/create.json", nil, "userId", userId, "groupId", groupId, "groupName", groupName)
+}
+
+// Dismiss Dismisses an ultra group /ultragroup/dis.json
+func (c *UltraGroupClient) Dismiss(groupId string) (string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/dis.json", nil, "groupId", groupId)
+}
+
+// Join Adds a user to an ultra group /ultragroup/join.json
+func (c *UltraGroupClient) Join(userId, groupId string) (string, error) {
+	if err := ugRequire("userId", userId, "groupId", groupId); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/join.json", nil, "userId", userId, "groupId", groupId)
+}
+
+// Quit Removes a user from an ultra group /ultragroup/quit.json
+func (c *UltraGroupClient) Quit(userId, groupId string) (string, error) {
+	if err := ugRequire("userId", userId, "groupId", groupId); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/quit.json", nil, "userId", userId, "groupId", groupId)
+}
+
+// Refresh Updates the name of an ultra group /ultragroup/refresh.json
+func (c *UltraGroupClient) Refresh(groupId, groupName string) (string, error) {
+	if err := ugRequire("groupId", groupId, "groupName", groupName); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/refresh.json", nil, "groupId", groupId, "groupName", groupName)
+}
+
+// MemberExists Checks whether a user is a member of an ultra group /ultragroup/member/exist.json
+func (c *UltraGroupClient) MemberExists(groupId, userId string) (bool, string, error) {
+	if err := ugRequire("groupId", groupId, "userId", userId); err != nil {
+		return false, "", err
+	}
+	resp := struct {
+		Status bool `json:"status"`
+	}{}
+	requestId, err := c.form("/ultragroup/member/exist.json", &resp, "groupId", groupId, "userId", userId)
+	return resp.Status, requestId, err
+}
+
+// Members Queries the members of an ultra group, page by page. 0 uses the server default /v2/ultragroups/{groupId}/users
+func (c *UltraGroupClient) Members(groupId string, page, size int) ([]UGUserInfo, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return nil, "", err
+	}
+	var resp RespDataArray
+	requestId, err := c.rest(httplib.Get(c.rc.rongCloudURI+"/v2/ultragroups/"+groupId+"/users"), &resp,
+		"page", ugPositive(page), "size", ugPositive(size))
+	var users []UGUserInfo
+	for _, v := range resp.Data["users"] {
+		users = append(users, UGUserInfo{Id: fmt.Sprint(v["id"])})
+	}
+	return users, requestId, err
+}
+
+// GroupsOfUser Queries the ultra groups a user belongs to, page by page. 0 uses the server default /v2/ultragroups/users/{userId}/groups
+func (c *UltraGroupClient) GroupsOfUser(userId string, page, size int) ([]UGGroupInfo, string, error) {
+	if err := ugRequire("userId", userId); err != nil {
+		return nil, "", err
+	}
+	var resp RespDataArray
+	requestId, err := c.rest(httplib.Get(c.rc.rongCloudURI+"/v2/ultragroups/users/"+userId+"/groups"), &resp,
+		"page", ugPositive(page), "size", ugPositive(size))
+	var groups []UGGroupInfo
+	for _, v := range resp.Data["groups"] {
+		groups = append(groups, UGGroupInfo{GroupId: fmt.Sprint(v["group_id"]), GroupName: fmt.Sprint(v["group_name"])})
+	}
+	return groups, requestId, err
+}
+
+// Send Sends a message, optionally to selected members only /v2/message/ultragroup/send
+func (c *UltraGroupClient) Send(msg UGMessage) (string, error) {
+	if err := ugRequire("fromUserId", msg.FromUserId); err != nil {
+		return "", err
+	}
+	if len(msg.ToGroupIds) == 0 {
+		return "", RCErrorNew(1002, "param 'toGroupIds' is required")
+	}
+	req := httplib.Post(c.rc.rongCloudURI + "/v2/message/ultragroup/send")
+	if _, err := req.JSONBody(msg); err != nil {
+		return "", err
+	}
+	return c.rest(req, nil)
+}
+
+// Publish Sends a message to up to 3 ultra groups /message/ultragroup/publish.json
+func (c *UltraGroupClient) Publish(msg UGPublishMessage) (MessageResult, string, error) {
+	result := MessageResult{}
+	if err := ugRequire("fromUserId", msg.FromUserId, "objectName", msg.ObjectName, "content", msg.Content); err != nil {
+		return result, "", err
+	}
+	if n := len(msg.ToGroupIds); n <= 0 || n > 3 {
+		return result, "", RCErrorNew(1002, "param 'toGroupIds' must contain 1 to 3 groups")
+	}
+
+	body := map[string]interface{}{
+		"fromUserId": msg.FromUserId,
+		"toGroupIds": msg.ToGroupIds,
+		"objectName": msg.ObjectName,
+		"content":    msg.Content,
+		"expansion":  msg.Expansion,
+		"isCounted":  msg.IsCounted,
+	}
+	optional := map[string]string{
+		"pushContent":      msg.PushContent,
+		"pushData":         msg.PushData,
+		"isPersisted":      msg.IsPersisted,
+		"isMentioned":      msg.IsMentioned,
+		"contentAvailable": msg.ContentAvailable,
+		"busChannel":       msg.BusChannel,
+		"extraContent":     msg.ExtraContent,
+	}
+	for k, v := range optional {
+		if v != "" {
+			body[k] = v
+		}
+	}
+	if msg.IsMentioned != "1" {
+		body["unreadCountFlag"] = msg.UnreadCountFlag
+	}
+	if msg.IsCounted != "1" && msg.IsCounted != "0" {
+		body["isCounted"] = "1"
+	}
+	if msg.PushExt != nil {
+		encPushExt, err := json.Marshal(msg.PushExt)
+		if err != nil {
+			return result, "", err
+		}
+		body["pushExt"] = string(encPushExt)
+	}
+
+	requestId, err := c.json("/message/ultragroup/publish.json", body, &result)
+	return result, requestId, err
+}
+
+// History Queries messages of a channel sent in (startTime, endTime], at most 14 days apart /ultragroup/hismsg/query.json
+/*
+*@param  pageSize: Default 20, maximum 100.
+ */
+func (c *UltraGroupClient) History(groupId, busChannel string, startTime, endTime int64, fromUserId string, pageSize int) ([]UGHisMsgQueryData, string, error) {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+	var resp UGHisMsgQueryResp
+	requestId, err := c.form("/ultragroup/hismsg/query.json", &resp,
+		"groupId", groupId,
+		"busChannel", busChannel,
+		"startTime", strconv.FormatInt(startTime, 10),
+		"endTime", strconv.FormatInt(endTime, 10),
+		"fromUserId", fromUserId,
+		"pageSize", strconv.Itoa(pageSize))
+	return resp.Data, requestId, err
+}
+
+// HistoryAround Queries the messages before and after a message /ultragroup/hismsg/msgid/query.json
+/*
+*@param  prevNum: Messages before msgUID, default 10, maximum 50. Negative values use the default.
+*@param  lastNum: Messages after msgUID, default 10, maximum 50. Negative values use the default.
+ */
+func (c *UltraGroupClient) HistoryAround(groupId, busChannel, msgUID string, prevNum, lastNum int) ([]UGHisMsgIdQueryData, string, error) {
+	prev, last := "", ""
+	if prevNum >= 0 {
+		prev = strconv.Itoa(prevNum)
+	}
+	if lastNum >= 0 {
+		last = strconv.Itoa(lastNum)
+	}
+	var resp UGHisMsgIdQueryResp
+	requestId, err := c.form("/ultragroup/hismsg/msgid/query.json", &resp,
+		"groupId", groupId, "busChannel", busChannel, "msgUID", msgUID, "prevNum", prev, "lastNum", last)
+	return resp.Data, requestId, err
+}
+
+// ExpansionSet Sets expansion key/values of a message, at most 100 per call /ultragroup/message/expansion/set.json
+func (c *UltraGroupClient) ExpansionSet(groupId, userId, msgUID, busChannel string, extra map[string]string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userId", userId, "msgUID", msgUID); err != nil {
+		return "", err
+	}
+	if len(extra) == 0 || len(extra) > 100 {
+		return "", RCErrorNew(1002, "param 'extra' must contain 1 to 100 keys")
+	}
+	encExtra, err := json.Marshal(extra)
+	if err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/message/expansion/set.json", nil,
+		"msgUID", msgUID, "userId", userId, "groupId", groupId, "extraKeyVal", string(encExtra), "busChannel", busChannel)
+}
+
+// ExpansionDelete Deletes expansion keys of a message, at most 100 per call /ultragroup/message/expansion/delete.json
+func (c *UltraGroupClient) ExpansionDelete(groupId, userId, msgUID, busChannel string, keys ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userId", userId, "msgUID", msgUID); err != nil {
+		return "", err
+	}
+	if len(keys) == 0 || len(keys) > 100 {
+		return "", RCErrorNew(1002, "param 'keys' must contain 1 to 100 keys")
+	}
+	encKeys, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/message/expansion/delete.json", nil,
+		"msgUID", msgUID, "userId", userId, "groupId", groupId, "extraKey", string(encKeys), "busChannel", busChannel)
+}
+
+// ExpansionQuery Queries the expansion key/values of a message /ultragroup/message/expansion/query.json
+func (c *UltraGroupClient) ExpansionQuery(groupId, msgUID, busChannel string) ([]UGMessageExpansionItem, string, error) {
+	if err := ugRequire("groupId", groupId, "msgUID", msgUID); err != nil {
+		return nil, "", err
+	}
+	resp := struct {
+		ExtraContent map[string]struct {
+			V  string `json:"v"`
+			Ts int64  `json:"ts"`
+		} `json:"extraContent"`
+	}{}
+	requestId, err := c.form("/ultragroup/message/expansion/query.json", &resp,
+		"msgUID", msgUID, "groupId", groupId, "busChannel", busChannel)
+	var items []UGMessageExpansionItem
+	for key, val := range resp.ExtraContent {
+		items = append(items, UGMessageExpansionItem{Key: key, Value: val.V, Timestamp: val.Ts})
+	}
+	return items, requestId, err
+}
+
+// MuteMembersAdd Mutes up to 20 members in an ultra group, or in one channel when busChannel is set /ultragroup/userbanned/add.json
+func (c *UltraGroupClient) MuteMembersAdd(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequireUsers(groupId, userIds, 20); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/userbanned/add.json", nil,
+		"groupId", groupId, "userIds", strings.Join(userIds, ","), "busChannel", busChannel)
+}
+
+// MuteMembersRemove Unmutes up to 20 members /ultragroup/userbanned/del.json
+func (c *UltraGroupClient) MuteMembersRemove(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequireUsers(groupId, userIds, 20); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/userbanned/del.json", nil,
+		"groupId", groupId, "userIds", strings.Join(userIds, ","), "busChannel", busChannel)
+}
+
+// MuteMembersQuery Queries muted members, page by page. 0 uses the server default /ultragroup/userbanned/get.json
+func (c *UltraGroupClient) MuteMembersQuery(groupId, busChannel string, page, pageSize int) ([]UGUserInfo, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return nil, "", err
+	}
+	resp := struct {
+		Users []UGUserInfo `json:"users"`
+	}{}
+	requestId, err := c.form("/ultragroup/userbanned/get.json", &resp,
+		"groupId", groupId, "busChannel", busChannel, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+	return resp.Users, requestId, err
+}
+
+// MuteAllSet Mutes or unmutes all members of an ultra group, or of one channel /ultragroup/globalbanned/set.json
+func (c *UltraGroupClient) MuteAllSet(groupId, busChannel string, status bool) (string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/globalbanned/set.json", nil,
+		"groupId", groupId, "status", strconv.FormatBool(status), "busChannel", busChannel)
+}
+
+// MuteAllQuery Queries whether all members of an ultra group, or of one channel, are muted /ultragroup/globalbanned/get.json
+func (c *UltraGroupClient) MuteAllQuery(groupId, busChannel string) (bool, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return false, "", err
+	}
+	resp := struct {
+		Status bool `json:"status"`
+	}{}
+	requestId, err := c.form("/ultragroup/globalbanned/get.json", &resp, "groupId", groupId, "busChannel", busChannel)
+	return resp.Status, requestId, err
+}
+
+// MuteWhitelistAdd Adds up to 20 users to the mute exceptions list /ultragroup/banned/whitelist/add.json
+func (c *UltraGroupClient) MuteWhitelistAdd(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequireUsers(groupId, userIds, 20); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/banned/whitelist/add.json", nil,
+		"groupId", groupId, "userIds", strings.Join(userIds, ","), "busChannel", busChannel)
+}
+
+// MuteWhitelistRemove Removes up to 20 users from the mute exceptions list /ultragroup/banned/whitelist/del.json
+func (c *UltraGroupClient) MuteWhitelistRemove(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequireUsers(groupId, userIds, 20); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/banned/whitelist/del.json", nil,
+		"groupId", groupId, "userIds", strings.Join(userIds, ","), "busChannel", busChannel)
+}
+
+// MuteWhitelistQuery Queries the mute exceptions list, page by page. 0 uses the server default /ultragroup/banned/whitelist/get.json
+func (c *UltraGroupClient) MuteWhitelistQuery(groupId, busChannel string, page, pageSize int) ([]UGUserInfo, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return nil, "", err
+	}
+	resp := struct {
+		Users []UGUserInfo `json:"users"`
+	}{}
+	requestId, err := c.form("/ultragroup/banned/whitelist/get.json", &resp,
+		"groupId", groupId, "busChannel", busChannel, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+	return resp.Users, requestId, err
+}
+
+// ChannelCreate Creates a channel of type UGChannelTypePublic or UGChannelTypePrivate /ultragroup/channel/create.json
+func (c *UltraGroupClient) ChannelCreate(groupId, busChannel string, channelType int) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/create.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "type", strconv.Itoa(channelType))
+}
+
+// ChannelDelete Deletes a channel /ultragroup/channel/del.json
+func (c *UltraGroupClient) ChannelDelete(groupId, busChannel string) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/del.json", nil, "groupId", groupId, "busChannel", busChannel)
+}
+
+// ChannelQuery Queries the channels of an ultra group, page by page. 0 uses the server default /ultragroup/channel/get.json
+func (c *UltraGroupClient) ChannelQuery(groupId string, page, limit int) ([]UltraGroupChannelGetResponseItem, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return nil, "", err
+	}
+	resp := struct {
+		Channels []UltraGroupChannelGetResponseItem `json:"channelList"`
+	}{}
+	requestId, err := c.form("/ultragroup/channel/get.json", &resp,
+		"groupId", groupId, "page", ugPositive(page), "limit", ugPositive(limit))
+	return resp.Channels, requestId, err
+}
+
+// ChannelTypeChange Switches a channel between UGChannelTypePublic and UGChannelTypePrivate /ultragroup/channel/type/change.json
+func (c *UltraGroupClient) ChannelTypeChange(groupId, busChannel string, channelType int) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/type/change.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "type", strconv.Itoa(channelType))
+}
+
+// PrivateUsersAdd Adds users to the allowlist of a private channel /ultragroup/channel/private/users/add.json
+func (c *UltraGroupClient) PrivateUsersAdd(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel, "userIds", strings.Join(userIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/private/users/add.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "userIds", strings.Join(userIds, ","))
+}
+
+// PrivateUsersRemove Removes users from the allowlist of a private channel /ultragroup/channel/private/users/del.json
+func (c *UltraGroupClient) PrivateUsersRemove(groupId, busChannel string, userIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel, "userIds", strings.Join(userIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/private/users/del.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "userIds", strings.Join(userIds, ","))
+}
+
+// PrivateUsersQuery Queries the allowlist of a private channel, page by page. 0 uses the server default /ultragroup/channel/private/users/get.json
+func (c *UltraGroupClient) PrivateUsersQuery(groupId, busChannel string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel); err != nil {
+		return nil, "", err
+	}
+	var resp UGChannelPrivateUserGetObj
+	requestId, err := c.form("/ultragroup/channel/private/users/get.json", &resp,
+		"groupId", groupId, "busChannel", busChannel, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+	return resp.Users, requestId, err
+}
+
+// UserGroupCreate Creates user groups /ultragroup/usergroup/add.json
+func (c *UltraGroupClient) UserGroupCreate(groupId string, userGroupIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userGroupIds", strings.Join(userGroupIds, ",")); err != nil {
+		return "", err
+	}
+	userGroups := make([]UGUserGroupInfo, 0, len(userGroupIds))
+	for _, id := range userGroupIds {
+		userGroups = append(userGroups, UGUserGroupInfo{UserGroupId: id})
+	}
+	return c.json("/ultragroup/usergroup/add.json", map[string]interface{}{
+		"groupId":    groupId,
+		"userGroups": userGroups,
+	}, nil)
+}
+
+// UserGroupDelete Deletes user groups /ultragroup/usergroup/del.json
+func (c *UltraGroupClient) UserGroupDelete(groupId string, userGroupIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userGroupIds", strings.Join(userGroupIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/usergroup/del.json", nil, "groupId", groupId, "userGroupIds", strings.Join(userGroupIds, ","))
+}
+
+// UserGroupQuery Queries the user groups of an ultra group, page by page. 0 uses the server default /ultragroup/usergroup/query.json
+func (c *UltraGroupClient) UserGroupQuery(groupId string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return nil, "", err
+	}
+	resp := struct {
+		UserGroups []UGUserGroupInfo `json:"userGroups"`
+	}{}
+	requestId, err := c.form("/ultragroup/usergroup/query.json", &resp,
+		"groupId", groupId, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+	ids := make([]string, 0, len(resp.UserGroups))
+	for _, g := range resp.UserGroups {
+		ids = append(ids, g.UserGroupId)
+	}
+	return ids, requestId, err
+}
+
+// UserGroupUsersAdd Adds users to a user group /ultragroup/usergroup/user/add.json
+func (c *UltraGroupClient) UserGroupUsersAdd(groupId, userGroupId string, userIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userGroupId", userGroupId, "userIds", strings.Join(userIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/usergroup/user/add.json", nil,
+		"groupId", groupId, "userGroupId", userGroupId, "userIds", strings.Join(userIds, ","))
+}
+
+// UserGroupUsersRemove Removes users from a user group /ultragroup/usergroup/user/del.json
+func (c *UltraGroupClient) UserGroupUsersRemove(groupId, userGroupId string, userIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "userGroupId", userGroupId, "userIds", strings.Join(userIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/usergroup/user/del.json", nil,
+		"groupId", groupId, "userGroupId", userGroupId, "userIds", strings.Join(userIds, ","))
+}
+
+// UserUserGroupQuery Queries the user groups a user belongs to, page by page. 0 uses the server default /ultragroup/user/usergroup/query.json
+func (c *UltraGroupClient) UserUserGroupQuery(groupId, userId string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId, "userId", userId); err != nil {
+		return nil, "", err
+	}
+	return c.ids("/ultragroup/user/usergroup/query.json",
+		"groupId", groupId, "userId", userId, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+}
+
+// ChannelUserGroupBind Binds user groups to a channel /ultragroup/channel/usergroup/bind.json
+func (c *UltraGroupClient) ChannelUserGroupBind(groupId, busChannel string, userGroupIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel, "userGroupIds", strings.Join(userGroupIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/usergroup/bind.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "userGroupIds", strings.Join(userGroupIds, ","))
+}
+
+// ChannelUserGroupUnbind Unbinds user groups from a channel /ultragroup/channel/usergroup/unbind.json
+func (c *UltraGroupClient) ChannelUserGroupUnbind(groupId, busChannel string, userGroupIds ...string) (string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel, "userGroupIds", strings.Join(userGroupIds, ",")); err != nil {
+		return "", err
+	}
+	return c.form("/ultragroup/channel/usergroup/unbind.json", nil,
+		"groupId", groupId, "busChannel", busChannel, "userGroupIds", strings.Join(userGroupIds, ","))
+}
+
+// ChannelUserGroupQuery Queries the user groups bound to a channel, page by page. 0 uses the server default /ultragroup/channel/usergroup/query.json
+func (c *UltraGroupClient) ChannelUserGroupQuery(groupId, busChannel string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId, "busChannel", busChannel); err != nil {
+		return nil, "", err
+	}
+	return c.ids("/ultragroup/channel/usergroup/query.json",
+		"groupId", groupId, "busChannel", busChannel, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+}
+
+// UserGroupChannelQuery Queries the channels bound to a user group, page by page. 0 uses the server default /ultragroup/usergroup/channel/query.json
+func (c *UltraGroupClient) UserGroupChannelQuery(groupId, userGroupId string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId, "userGroupId", userGroupId); err != nil {
+		return nil, "", err
+	}
+	return c.ids("/ultragroup/usergroup/channel/query.json",
+		"groupId", groupId, "userGroupId", userGroupId, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+}
+
+// UserChannelQuery Queries the private channels whose allowlist contains a user, page by page. 0 uses the server default /ultragroup/user/channel/query.json
+func (c *UltraGroupClient) UserChannelQuery(groupId, userId string, page, pageSize int) ([]string, string, error) {
+	if err := ugRequire("groupId", groupId, "userId", userId); err != nil {
+		return nil, "", err
+	}
+	return c.ids("/ultragroup/user/channel/query.json",
+		"groupId", groupId, "userId", userId, "page", ugPositive(page), "pageSize", ugPositive(pageSize))
+}
+
+// NotDisturbSet Sets the default Do Not Disturb level of an ultra group or channel, one of the UGUnPushLevel* constants /ultragroup/notdisturb/set.json
+func (c *UltraGroupClient) NotDisturbSet(groupId, busChannel string, unPushLevel int) (string, error) {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return "", err
+	}
+	switch unPushLevel {
+	case UGUnPushLevelAllMessage, UGUnPushLevelNotSet, UGUnPushLevelAtMessage,
+		UGUnPushLevelAtUser, UGUnPushLevelAtAllGroupMembers, UGUnPushLevelNotRecv:
+	default:
+		return "", RCErrorNew(1002, "param 'unPushLevel' was wrong")
+	}
+	return c.form("/ultragroup/notdisturb/set.json", nil,
+		"groupId", groupId, "unpushLevel", strconv.Itoa(unPushLevel), "busChannel", busChannel)
+}
+
+// NotDisturbQuery Queries the default Do Not Disturb level of an ultra group or channel /ultragroup/notdisturb/get.json
+func (c *UltraGroupClient) NotDisturbQuery(groupId, busChannel string) (UGNotDisturbGetResponses, string, error) {
+	var resp UGNotDisturbGetResponses
+	if err := ugRequire("groupId", groupId); err != nil {
+		return resp, "", err
+	}
+	requestId, err := c.form("/ultragroup/notdisturb/get.json", &resp, "groupId", groupId, "busChannel", busChannel)
+	return resp, requestId, err
+}
+
+// form sends a signed form request to a v1 endpoint. params are key/value pairs, empty values are omitted.
+func (c *UltraGroupClient) form(path string, result interface{}, params ...string) (string, error) {
+	req := httplib.Post(c.rc.rongCloudURI + path)
+	req.SetTimeout(time.Second*c.rc.timeout, time.Second*c.rc.timeout)
+	requestId := c.rc.fillHeader(req)
+	ugParams(req, params)
+	return requestId, ugDecode(c.rc.do(req))(result)
+}
+
+// json sends a signed JSON request to a v1 endpoint.
+func (c *UltraGroupClient) json(path string, body, result interface{}) (string, error) {
+	req := httplib.Post(c.rc.rongCloudURI + path)
+	req.SetTimeout(time.Second*c.rc.timeout, time.Second*c.rc.timeout)
+	requestId := c.rc.fillHeader(req)
+	if _, err := req.JSONBody(body); err != nil {
+		return requestId, err
+	}
+	return requestId, ugDecode(c.rc.do(req))(result)
+}
+
+// rest sends a request to a v2 endpoint, converting CodeResultV2 errors to CodeResult.
+func (c *UltraGroupClient) rest(req *httplib.BeegoHTTPRequest, result interface{}, params ...string) (string, error) {
+	req.SetTimeout(time.Second*c.rc.timeout, time.Second*c.rc.timeout)
+	requestId := c.rc.fillHeaderV2(req)
+	ugParams(req, params)
+	body, err := c.rc.doV2(req)
+	if e, ok := err.(CodeResultV2); ok {
+		err = CodeResult{Code: e.Code, ErrorMessage: e.Message}
+	}
+	return requestId, ugDecode(body, err)(result)
+}
+
+// ids sends a form request whose response carries a list of IDs in data.
+func (c *UltraGroupClient) ids(path string, params ...string) ([]string, string, error) {
+	resp := struct {
+		Data []string `json:"data"`
+	}{}
+	requestId, err := c.form(path, &resp, params...)
+	return resp.Data, requestId, err
+}
+
+func ugParams(req *httplib.BeegoHTTPRequest, params []string) {
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] != "" {
+			req.Param(params[i], params[i+1])
+		}
+	}
+}
+
+// ugDecode returns a function that decodes body into result, unless err is set or result is nil.
+func ugDecode(body []byte, err error) func(result interface{}) error {
+	return func(result interface{}) error {
+		if err != nil || result == nil || len(body) == 0 {
+			return err
+		}
+		return json.Unmarshal(body, result)
+	}
+}
+
+// ugRequire checks name/value pairs and reports the first empty value.
+func ugRequire(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			return RCErrorNew(1002, "param '"+pairs[i]+"' is required")
+		}
+	}
+	return nil
+}
+
+func ugRequireUsers(groupId string, userIds []string, max int) error {
+	if err := ugRequire("groupId", groupId); err != nil {
+		return err
+	}
+	if len(userIds) == 0 {
+		return RCErrorNew(1002, "param 'userIds' is required")
+	}
+	if len(userIds) > max {
+		return RCErrorNew(1002, "param 'userIds' can not exceed "+strconv.Itoa(max)+" users")
+	}
+	return nil
+}
+
+// ugPositive formats n, or returns "" so the server default applies.
+func ugPositive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
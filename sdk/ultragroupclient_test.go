@@ -0,0 +1,205 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUltraGroupClient_Validation(t *testing.T) {
+	ug := NewRongCloud("key", "secret", REGION_BJ).UltraGroup()
+
+	requestId, err := ug.Create("u01", "", "name")
+	if err == nil || requestId != "" {
+		t.Errorf("expected local rejection, got %q %v", requestId, err)
+	}
+	if e, ok := err.(CodeResult); !ok || e.Code != 1002 {
+		t.Errorf("expected CodeResult 1002, got %#v", err)
+	}
+
+	users := make([]string, 21)
+	if _, err = ug.MuteMembersAdd("ug01", "", users...); err == nil {
+		t.Error("expected error for more than 20 users")
+	}
+	if _, _, err = ug.Publish(UGPublishMessage{FromUserId: "u01", ObjectName: "RC:TxtMsg", Content: "{}",
+		ToGroupIds: []string{"1", "2", "3", "4"}}); err == nil {
+		t.Error("expected error for more than 3 groups")
+	}
+	if _, err = ug.NotDisturbSet("ug01", "", 3); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestRongCloud_UGDeprecatedV2(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/ultragroups/ug01/muted-users":
+			_, _ = w.Write([]byte(`{"code": 10000, "data": {"users": [{"id": "u01", "time": "2024-01-01"}]}}`))
+		case r.URL.Path == "/v2/ultragroups/ug02/allowed-users", strings.Contains(r.URL.Path+string(body), "ug02"):
+			_, _ = w.Write([]byte(`{"code": 20004, "msg": "group not found"}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/"):
+			_, _ = w.Write([]byte(`{"code": 10000}`))
+		default:
+			_, _ = w.Write([]byte(`{"code": 200}`))
+		}
+	}))
+	defer server.Close()
+	rc := newRongCloud("key", "secret", NewRegion(server.URL, ""))
+
+	userIds := make([]string, 45)
+	for i := range userIds {
+		userIds[i] = "u"
+	}
+	if err, requestId := rc.UGGroupMuteMembersAdd("ug01", userIds); err != nil || requestId == "" {
+		t.Fatalf("add = %v, %q", err, requestId)
+	}
+	users, err, _ := rc.UGGroupMuteMembersGetList("ug01")
+	if err != nil || len(users) != 1 || users[0].Id != "u01" || users[0].MutedTime != "2024-01-01" {
+		t.Errorf("list = %+v, %v", users, err)
+	}
+	if len(requests) != 2 || requests[0][:38] != "POST /v2/ultragroups/ug01/muted-users " {
+		t.Errorf("requests = %q", requests)
+	}
+
+	err, _ = rc.UGGroupMutedWhitelistAdd("ug02", []string{"u01"})
+	if e, ok := err.(CodeResultV2); !ok || e.Code != 20004 {
+		t.Errorf("expected CodeResultV2 20004, got %#v", err)
+	}
+	if err, _ := rc.UGChannelCreate("", "c01"); err == nil {
+		t.Error("expected error for empty groupId")
+	} else if _, ok := err.(CodeResultV2); !ok {
+		t.Errorf("expected CodeResultV2, got %#v", err)
+	}
+
+	// The sends take a list of groups, which is empty rather than holding an empty ID.
+	groups := func(groupId string) []string {
+		if groupId == "" {
+			return nil
+		}
+		return []string{groupId}
+	}
+	wrappers := []struct {
+		request string
+		call    func(groupId string) error
+	}{
+		{"POST /v2/ultragroups", func(g string) error { err, _ := rc.UGGroupCreate("u01", g, "name"); return err }},
+		{"DELETE /v2/ultragroups/ug01", func(g string) error { err, _ := rc.UGGroupDismiss(g); return err }},
+		{"POST /v2/ultragroups/ug01/users/u01", func(g string) error { err, _ := rc.UGGroupJoin("u01", g); return err }},
+		{"DELETE /v2/ultragroups/ug01/users/u01", func(g string) error { err, _ := rc.UGGroupQuit("u01", g); return err }},
+		{"PUT /v2/ultragroups/ug01", func(g string) error { err, _ := rc.UGGroupUpdate(g, "name"); return err }},
+		{"GET /v2/ultragroups/users/ug01/groups", func(g string) error {
+			// The user ID is the only input, so the group ID stands in for it.
+			_, err, _ := rc.UGQueryUserGroups(g, 1, 20)
+			return err
+		}},
+		{"GET /v2/ultragroups/ug01/users", func(g string) error { _, err, _ := rc.UGQueryGroupUsers(g, 1, 20); return err }},
+		{"POST /v2/message/ultragroup/send", func(g string) error {
+			err, _ := rc.UGGroupSend(UGMessage{FromUserId: "u01", ToGroupIds: groups(g)})
+			return err
+		}},
+		{"POST /ultragroup/message/expansion/set.json", func(g string) error {
+			return rc.UGMessageExpansionSet(g, "u01", "msg01", "", map[string]string{"k": "v"})
+		}},
+		{"POST /ultragroup/message/expansion/delete.json", func(g string) error {
+			return rc.UGMessageExpansionDelete(g, "u01", "msg01", "", "k")
+		}},
+		{"POST /ultragroup/message/expansion/query.json", func(g string) error {
+			_, err := rc.UGMessageExpansionQuery(g, "msg01", "")
+			return err
+		}},
+		{"POST /message/ultragroup/publish.json", func(g string) error {
+			_, err := rc.UGMessagePublish("u01", "RC:TxtMsg", "{}", "", "", "", "", "", "", "", "", false, false, nil, groups(g)...)
+			return err
+		}},
+		{"POST /ultragroup/member/exist.json", func(g string) error { _, err := rc.UGMemberExists(g, "u01"); return err }},
+		{"POST /ultragroup/notdisturb/set.json", func(g string) error { return rc.UGNotDisturbSet(g, UGUnPushLevelAtMessage, "") }},
+	}
+	for _, w := range wrappers {
+		requests = nil
+		if err := w.call("ug01"); err != nil {
+			t.Errorf("%s: %v", w.request, err)
+		}
+		if len(requests) != 1 || !strings.HasPrefix(requests[0], w.request+" ") {
+			t.Errorf("%s: requests = %q", w.request, requests)
+		}
+		if err := w.call("ug02"); err == nil {
+			t.Errorf("%s: expected error", w.request)
+		} else if e, ok := err.(CodeResultV2); !ok || e.Code != 20004 {
+			t.Errorf("%s: expected CodeResultV2 20004, got %#v", w.request, err)
+		}
+		if err := w.call(""); err == nil {
+			t.Errorf("%s: expected error for empty groupId", w.request)
+		} else if _, ok := err.(CodeResultV2); !ok {
+			t.Errorf("%s: expected CodeResultV2, got %#v", w.request, err)
+		}
+	}
+}
+
+func TestUltraGroupClient_QueryPaging(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		queries = append(queries, r.URL.Path+" page="+r.Form.Get("page")+" pageSize="+r.Form.Get("pageSize")+" size="+r.Form.Get("size"))
+		_, _ = w.Write([]byte(`{"code": 200}`))
+	}))
+	defer server.Close()
+	ug := newRongCloud("key", "secret", NewRegion(server.URL, "")).UltraGroup()
+
+	calls := []func() error{
+		func() error { _, _, err := ug.Members("ug01", 0, 0); return err },
+		func() error { _, _, err := ug.GroupsOfUser("u01", 0, 0); return err },
+		func() error { _, _, err := ug.PrivateUsersQuery("ug01", "c01", 0, 0); return err },
+		func() error { _, _, err := ug.UserGroupQuery("ug01", 0, 0); return err },
+		func() error { _, _, err := ug.UserUserGroupQuery("ug01", "u01", 0, 0); return err },
+		func() error { _, _, err := ug.ChannelUserGroupQuery("ug01", "c01", 0, 0); return err },
+		func() error { _, _, err := ug.UserGroupChannelQuery("ug01", "ug01", 0, 0); return err },
+		func() error { _, _, err := ug.UserChannelQuery("ug01", "u01", 0, 0); return err },
+	}
+	for _, call := range calls {
+		if err := call(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(queries) != len(calls) {
+		t.Fatalf("queries = %q", queries)
+	}
+	for _, q := range queries {
+		if !strings.HasSuffix(q, " page= pageSize= size=") {
+			t.Errorf("zero paging was sent: %s", q)
+		}
+	}
+
+	queries = nil
+	if _, _, err := ug.UserGroupQuery("ug01", 2, 50); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0] != "/ultragroup/usergroup/query.json page=2 pageSize=50 size=" {
+		t.Errorf("queries = %q", queries)
+	}
+}
+
+func TestUltraGroupClient_Create(t *testing.T) {
+	ug := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	).UltraGroup()
+
+	requestId, err := ug.Create("u01", "ug_client_01", "ug_client_01")
+	if err != nil {
+		t.Errorf("err:%v requestId:%s", err, requestId)
+		return
+	}
+	channels, requestId, err := ug.ChannelQuery("ug_client_01", 1, 20)
+	if err != nil {
+		t.Errorf("err:%v requestId:%s", err, requestId)
+		return
+	}
+	t.Logf("channels: %+v", channels)
+}
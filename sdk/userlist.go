@@ -0,0 +1,302 @@
+// Set semantics for user blacklists and whitelists, and an app-wide block manager
+
+package sdk
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// USER_LIST_MAX_BATCH Maximum number of users per blacklist or whitelist add or remove request
+	USER_LIST_MAX_BATCH = 20
+	// BLOCK_MAX_MINUTES Maximum duration of BlockAdd in minutes
+	BLOCK_MAX_MINUTES = 43200
+	// BLOCK_END_TIME_LAYOUT Layout of User.BlockEndTime as returned by BlockGetList, in Beijing time
+	BLOCK_END_TIME_LAYOUT = "2006-01-02 15:04:05"
+)
+
+var blockEndTimeLocation = time.FixedZone("CST", 8*60*60)
+
+// UserList The blacklist or whitelist of one user, with set semantics
+type UserList struct {
+	userId string
+	get    func(userId string) ([]string, error)
+	add    func(userId string, ids []string) error
+	remove func(userId string, ids []string) error
+}
+
+// UserListChange Operations performed by UserList.Replace
+type UserListChange struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// Blacklist Returns the blacklist of a user, backed by BlacklistGet, BlacklistAdd and BlacklistRemove.
+func (rc *RongCloud) Blacklist(userId string) *UserList {
+	return &UserList{
+		userId: userId,
+		get: func(userId string) ([]string, error) {
+			res, err := rc.BlacklistGet(userId)
+			return res.Users, err
+		},
+		add:    rc.BlacklistAdd,
+		remove: rc.BlacklistRemove,
+	}
+}
+
+// Whitelist Returns the whitelist of a user, backed by QueryWhiteList, AddWhiteList and RemoveWhiteList.
+func (rc *RongCloud) Whitelist(userId string) *UserList {
+	return &UserList{
+		userId: userId,
+		get: func(userId string) ([]string, error) {
+			res, err := rc.QueryWhiteList(userId)
+			return res.Users, err
+		},
+		add:    rc.AddWhiteList,
+		remove: rc.RemoveWhiteList,
+	}
+}
+
+// Get Returns the users in the list, sorted.
+func (l *UserList) Get() ([]string, error) {
+	if l.userId == "" {
+		return nil, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	users, err := l.get(l.userId)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(tagSet(users)), nil
+}
+
+// Contains Reports for each target whether it is in the list.
+/*
+*@param  targetIds: User IDs.
+*
+*@return map[string]bool: Membership keyed by user ID.
+*@return error
+ */
+func (l *UserList) Contains(targetIds ...string) (map[string]bool, error) {
+	users, err := l.Get()
+	if err != nil {
+		return nil, err
+	}
+	set := tagSet(users)
+	contains := make(map[string]bool, len(targetIds))
+	for _, targetId := range targetIds {
+		contains[targetId] = set[targetId]
+	}
+	return contains, nil
+}
+
+// Diff Computes the users to add and remove so that the list holds exactly want.
+/*
+*@param  want: Desired users.
+*
+*@return UserListChange: Sorted users to add and remove.
+*@return error
+ */
+func (l *UserList) Diff(want []string) (UserListChange, error) {
+	users, err := l.Get()
+	if err != nil {
+		return UserListChange{}, err
+	}
+	return UserListChange{Added: ugACLMissing(want, users), Removed: ugACLMissing(users, want)}, nil
+}
+
+// Replace Makes the list hold exactly want, adding and removing users in requests of at most USER_LIST_MAX_BATCH.
+/*
+*@param  want: Desired users.
+*
+*@return UserListChange: The operations performed, also when an error stops the run.
+*@return error
+ */
+func (l *UserList) Replace(want []string) (UserListChange, error) {
+	diff, err := l.Diff(want)
+	if err != nil {
+		return UserListChange{}, err
+	}
+	done := UserListChange{}
+	err = userListChunk(diff.Removed, func(chunk []string) error {
+		if err := l.remove(l.userId, chunk); err != nil {
+			return err
+		}
+		done.Removed = append(done.Removed, chunk...)
+		return nil
+	})
+	if err != nil {
+		return done, err
+	}
+	err = userListChunk(diff.Added, func(chunk []string) error {
+		if err := l.add(l.userId, chunk); err != nil {
+			return err
+		}
+		done.Added = append(done.Added, chunk...)
+		return nil
+	})
+	return done, err
+}
+
+func userListChunk(ids []string, fn func([]string) error) error {
+	for start := 0; start < len(ids); start += USER_LIST_MAX_BATCH {
+		end := start + USER_LIST_MAX_BATCH
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := fn(ids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BlockManager Tracks the blocked users of the App with their block expiry times, safe for concurrent use
+type BlockManager struct {
+	rc     *RongCloud
+	now    func() time.Time
+	lock   sync.RWMutex
+	blocks map[string]time.Time
+}
+
+// NewBlockManager creates a BlockManager. Call Load to read the current blocks.
+func (rc *RongCloud) NewBlockManager() *BlockManager {
+	return &BlockManager{rc: rc, now: time.Now, blocks: map[string]time.Time{}}
+}
+
+// Load Replaces the tracked blocks with the result of BlockGetList.
+func (m *BlockManager) Load() error {
+	res, err := m.rc.BlockGetList()
+	if err != nil {
+		return err
+	}
+	blocks := make(map[string]time.Time, len(res.Users))
+	for _, u := range res.Users {
+		end, err := ParseBlockEndTime(u.BlockEndTime)
+		if err != nil {
+			return err
+		}
+		blocks[u.UserID] = end
+	}
+	m.lock.Lock()
+	m.blocks = blocks
+	m.lock.Unlock()
+	return nil
+}
+
+// Expiry Returns when the block of a user ends, and false when the user is not blocked.
+func (m *BlockManager) Expiry(userId string) (time.Time, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	end, ok := m.blocks[userId]
+	if !ok || !end.After(m.now()) {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+// Blocked Returns the users whose block has not ended yet, sorted.
+func (m *BlockManager) Blocked() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	now := m.now()
+	var users []string
+	for userId, end := range m.blocks {
+		if end.After(now) {
+			users = append(users, userId)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Expiring Returns the blocked users whose block ends within the given period, sorted.
+func (m *BlockManager) Expiring(within time.Duration) []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	now := m.now()
+	var users []string
+	for userId, end := range m.blocks {
+		if end.After(now) && !end.After(now.Add(within)) {
+			users = append(users, userId)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Block Blocks a user with BlockAdd, rounding the duration up to whole minutes.
+/*
+*@param  userId: User ID.
+*@param  duration: Block duration, at most BLOCK_MAX_MINUTES minutes.
+*
+*@return error
+ */
+func (m *BlockManager) Block(userId string, duration time.Duration) error {
+	minutes := uint64((duration + time.Minute - 1) / time.Minute)
+	if minutes < 1 || minutes > BLOCK_MAX_MINUTES {
+		return RCErrorNew(1002, "Paramer 'duration' must be between 1 and "+strconv.Itoa(BLOCK_MAX_MINUTES)+" minutes")
+	}
+	if err := m.rc.BlockAdd(userId, minutes); err != nil {
+		return err
+	}
+	m.lock.Lock()
+	m.blocks[userId] = m.now().Add(time.Duration(minutes) * time.Minute)
+	m.lock.Unlock()
+	return nil
+}
+
+// Extend Blocks the users whose block ends within the given period again for duration, starting now.
+/*
+*@param  within: Blocks ending within this period are extended.
+*@param  duration: New block duration.
+*
+*@return []string: Users whose block was extended, also when an error stops the run.
+*@return error
+ */
+func (m *BlockManager) Extend(within, duration time.Duration) ([]string, error) {
+	var extended []string
+	for _, userId := range m.Expiring(within) {
+		if err := m.Block(userId, duration); err != nil {
+			return extended, err
+		}
+		extended = append(extended, userId)
+	}
+	return extended, nil
+}
+
+// Lift Unblocks users with BlockRemove.
+func (m *BlockManager) Lift(userIds ...string) error {
+	for _, userId := range userIds {
+		if err := m.rc.BlockRemove(userId); err != nil {
+			return err
+		}
+		m.lock.Lock()
+		delete(m.blocks, userId)
+		m.lock.Unlock()
+	}
+	return nil
+}
+
+// LiftAll Unblocks every blocked user.
+/*
+*@return []string: Users that were unblocked, also when an error stops the run.
+*@return error
+ */
+func (m *BlockManager) LiftAll() ([]string, error) {
+	var lifted []string
+	for _, userId := range m.Blocked() {
+		if err := m.Lift(userId); err != nil {
+			return lifted, err
+		}
+		lifted = append(lifted, userId)
+	}
+	return lifted, nil
+}
+
+// ParseBlockEndTime Parses User.BlockEndTime as returned by BlockGetList.
+func ParseBlockEndTime(s string) (time.Time, error) {
+	return time.ParseInLocation(BLOCK_END_TIME_LAYOUT, s, blockEndTimeLocation)
+}
@@ -0,0 +1,109 @@
+package sdk
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestUserList_Replace(t *testing.T) {
+	current := map[string]bool{"u02": true, "u03": true}
+	var calls []int
+	list := &UserList{
+		userId: "u01",
+		get: func(string) ([]string, error) {
+			return sortedKeys(current), nil
+		},
+		add: func(_ string, ids []string) error {
+			calls = append(calls, len(ids))
+			for _, id := range ids {
+				current[id] = true
+			}
+			return nil
+		},
+		remove: func(_ string, ids []string) error {
+			calls = append(calls, -len(ids))
+			for _, id := range ids {
+				delete(current, id)
+			}
+			return nil
+		},
+	}
+	want := []string{"u03"}
+	for i := 0; i < 25; i++ {
+		want = append(want, "n"+strconv.Itoa(i))
+	}
+
+	done, err := list.Replace(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(done.Removed, []string{"u02"}) || len(done.Added) != 25 {
+		t.Errorf("unexpected change: %+v", done)
+	}
+	if !reflect.DeepEqual(calls, []int{-1, 20, 5}) {
+		t.Errorf("unexpected batches: %v", calls)
+	}
+	contains, _ := list.Contains("u02", "u03")
+	if contains["u02"] || !contains["u03"] {
+		t.Errorf("unexpected contains: %v", contains)
+	}
+	if diff, _ := list.Diff(want); len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("expected an empty diff, got %+v", diff)
+	}
+}
+
+func TestBlockManager(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewRongCloud("key", "secret", REGION_BJ).NewBlockManager()
+	m.now = func() time.Time { return now }
+
+	end, err := ParseBlockEndTime("2024-01-01 20:30:00")
+	if err != nil || !end.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("unexpected end time: %v %v", end, err)
+	}
+	m.blocks = map[string]time.Time{"u01": end, "u02": now.Add(5 * time.Hour), "u03": now.Add(-time.Minute)}
+	if got := m.Blocked(); !reflect.DeepEqual(got, []string{"u01", "u02"}) {
+		t.Errorf("unexpected blocked users: %v", got)
+	}
+	if got := m.Expiring(time.Hour); !reflect.DeepEqual(got, []string{"u01"}) {
+		t.Errorf("unexpected expiring users: %v", got)
+	}
+	if _, ok := m.Expiry("u03"); ok {
+		t.Error("expired block reported")
+	}
+	if err := m.Block("u04", 0); err == nil {
+		t.Error("expected error for zero duration")
+	}
+}
+
+func TestRongCloud_BlacklistReplace(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	done, err := rc.Blacklist("u01").Replace([]string{"u02", "u03"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	t.Logf("change: %+v", done)
+}
+
+func TestRongCloud_BlockManager(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	m := rc.NewBlockManager()
+	if err := m.Load(); err != nil {
+		t.Error(err)
+		return
+	}
+	extended, err := m.Extend(time.Hour, 24*time.Hour)
+	t.Log(m.Blocked(), extended, err)
+}
@@ -0,0 +1,211 @@
+// Typed user profiles on top of UserProfileSet and UserProfilBatchQuery
+
+package sdk
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	// USER_EXT_PROFILE_PREFIX Every extended profile key starts with this prefix
+	USER_EXT_PROFILE_PREFIX = "ext_"
+	// USER_EXT_PROFILE_MAX_KEYS Maximum number of extended profile keys of a user
+	USER_EXT_PROFILE_MAX_KEYS = 20
+	// USER_EXT_PROFILE_KEY_MAX_LENGTH Maximum length of an extended profile key, prefix included
+	USER_EXT_PROFILE_KEY_MAX_LENGTH = 32
+	// USER_PROFILE_BATCH_MAX_USERS Maximum number of users per UserProfilBatchQuery request
+	USER_PROFILE_BATCH_MAX_USERS = 20
+
+	// UserProfileMerge Fields left empty keep their current value, extended keys set to nil are removed
+	UserProfileMerge = "merge"
+	// UserProfileReplace The profile is written as given, fields left empty are cleared
+	UserProfileReplace = "replace"
+)
+
+// UserExtProfile Extended user profile, every key starts with USER_EXT_PROFILE_PREFIX
+type UserExtProfile map[string]interface{}
+
+// UserProfile Basic and extended profile of a user
+type UserProfile struct {
+	UserId      string         `json:"-"`                     // User ID
+	Version     int            `json:"-"`                     // Profile version as returned by the server
+	Name        string         `json:"name,omitempty"`        // Nickname
+	PortraitUri string         `json:"portraitUri,omitempty"` // Avatar URL
+	Email       string         `json:"email,omitempty"`       // Email address
+	Birthday    string         `json:"birthday,omitempty"`    // Birthday such as "20011221"
+	Gender      *int           `json:"gender,omitempty"`      // 0: unknown, 1: male, 2: female. nil when not set
+	Location    string         `json:"location,omitempty"`    // Location
+	Role        *int           `json:"role,omitempty"`        // Role defined by the application, nil when not set
+	Level       *int           `json:"level,omitempty"`       // Level defined by the application, nil when not set
+	Ext         UserExtProfile `json:"-"`                     // Extended profile
+}
+
+// Validate Checks the extended profile keys.
+func (p UserProfile) Validate() error {
+	if len(p.Ext) > USER_EXT_PROFILE_MAX_KEYS {
+		return RCErrorNew(1002, "Paramer 'userExtProfile' holds more than "+strconv.Itoa(USER_EXT_PROFILE_MAX_KEYS)+" keys")
+	}
+	for key := range p.Ext {
+		if err := validateUserExtKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge Returns p with the non-empty and non-nil fields of update applied. Extended keys of update replace those of p,
+// and extended keys of update set to nil are removed.
+func (p UserProfile) Merge(update UserProfile) UserProfile {
+	merged := p
+	if update.Name != "" {
+		merged.Name = update.Name
+	}
+	if update.PortraitUri != "" {
+		merged.PortraitUri = update.PortraitUri
+	}
+	if update.Email != "" {
+		merged.Email = update.Email
+	}
+	if update.Birthday != "" {
+		merged.Birthday = update.Birthday
+	}
+	if update.Gender != nil {
+		merged.Gender = update.Gender
+	}
+	if update.Location != "" {
+		merged.Location = update.Location
+	}
+	if update.Role != nil {
+		merged.Role = update.Role
+	}
+	if update.Level != nil {
+		merged.Level = update.Level
+	}
+	merged.Ext = UserExtProfile{}
+	for key, value := range p.Ext {
+		merged.Ext[key] = value
+	}
+	for key, value := range update.Ext {
+		if value == nil {
+			delete(merged.Ext, key)
+			continue
+		}
+		merged.Ext[key] = value
+	}
+	return merged
+}
+
+// UserProfileGet Reads the profiles of any number of users,
+// splitting the users into UserProfilBatchQuery requests of at most USER_PROFILE_BATCH_MAX_USERS.
+/*
+*@param  userIds: User IDs.
+*
+*@return map[string]UserProfile: Profiles keyed by user ID. Users without a profile are missing.
+*@return error
+ */
+func (rc *RongCloud) UserProfileGet(userIds ...string) (map[string]UserProfile, error) {
+	if len(userIds) == 0 {
+		return nil, RCErrorNew(1002, "Paramer 'userIds' is required")
+	}
+	profiles := map[string]UserProfile{}
+	userIds = removeDuplicates(userIds)
+	for start := 0; start < len(userIds); start += USER_PROFILE_BATCH_MAX_USERS {
+		end := start + USER_PROFILE_BATCH_MAX_USERS
+		if end > len(userIds) {
+			end = len(userIds)
+		}
+		res, err := rc.UserProfilBatchQuery(strings.Join(userIds[start:end], ","))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.UserProfiles {
+			profile, err := ParseUserProfile(r)
+			if err != nil {
+				return nil, err
+			}
+			profiles[profile.UserId] = profile
+		}
+	}
+	return profiles, nil
+}
+
+// UserProfileUpdate Writes a user profile with UserProfileSet.
+// UserProfileMerge reads the current profile first and only changes the fields that are set in profile,
+// UserProfileReplace writes profile as given.
+/*
+*@param  profile: Profile, UserId is required.
+*@param  mode: UserProfileMerge or UserProfileReplace.
+*
+*@return UserProfile: The profile that was written.
+*@return error
+ */
+func (rc *RongCloud) UserProfileUpdate(profile UserProfile, mode string) (UserProfile, error) {
+	if profile.UserId == "" {
+		return profile, RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	switch mode {
+	case UserProfileMerge:
+		current, err := rc.UserProfileGet(profile.UserId)
+		if err != nil {
+			return profile, err
+		}
+		base, ok := current[profile.UserId]
+		if !ok {
+			base = UserProfile{UserId: profile.UserId}
+		}
+		profile = base.Merge(profile)
+	case UserProfileReplace:
+		for key, value := range profile.Ext {
+			if value == nil {
+				return profile, RCErrorNew(1002, "Extended profile key '"+key+"' has no value")
+			}
+		}
+	default:
+		return profile, RCErrorNew(1002, "Paramer 'mode' must be merge or replace")
+	}
+	if err := profile.Validate(); err != nil {
+		return profile, err
+	}
+
+	basic, err := json.Marshal(profile)
+	if err != nil {
+		return profile, err
+	}
+	ext := []byte("{}")
+	if len(profile.Ext) > 0 {
+		if ext, err = json.Marshal(profile.Ext); err != nil {
+			return profile, err
+		}
+	}
+	return profile, rc.UserProfileSet(profile.UserId, string(basic), string(ext))
+}
+
+// ParseUserProfile Decodes the JSON strings of a UserProfileResponse.
+func ParseUserProfile(r UserProfileResponse) (UserProfile, error) {
+	profile := UserProfile{}
+	if r.UserProfile != "" {
+		if err := json.Unmarshal([]byte(r.UserProfile), &profile); err != nil {
+			return profile, err
+		}
+	}
+	if r.UserExtProfile != "" {
+		if err := json.Unmarshal([]byte(r.UserExtProfile), &profile.Ext); err != nil {
+			return profile, err
+		}
+	}
+	profile.UserId = r.UserId
+	profile.Version = r.Version
+	return profile, nil
+}
+
+func validateUserExtKey(key string) error {
+	if !strings.HasPrefix(key, USER_EXT_PROFILE_PREFIX) || len(key) == len(USER_EXT_PROFILE_PREFIX) {
+		return RCErrorNew(1002, "Extended profile key '"+key+"' must start with '"+USER_EXT_PROFILE_PREFIX+"'")
+	}
+	if len(key) > USER_EXT_PROFILE_KEY_MAX_LENGTH {
+		return RCErrorNew(1002, "Extended profile key '"+key+"' is longer than "+strconv.Itoa(USER_EXT_PROFILE_KEY_MAX_LENGTH))
+	}
+	return nil
+}
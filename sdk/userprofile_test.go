@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseUserProfile(t *testing.T) {
+	profile, err := ParseUserProfile(UserProfileResponse{
+		UserId:         "u01",
+		Version:        3,
+		UserProfile:    `{"name":"Alice","birthday":"20011221","level":2}`,
+		UserExtProfile: `{"ext_team":"blue"}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	level := 2
+	want := UserProfile{UserId: "u01", Version: 3, Name: "Alice", Birthday: "20011221", Level: &level,
+		Ext: UserExtProfile{"ext_team": "blue"}}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+}
+
+func TestUserProfile_Merge(t *testing.T) {
+	level, zero := 2, 0
+	current := UserProfile{UserId: "u01", Name: "Alice", Level: &level, Ext: UserExtProfile{"ext_team": "blue", "ext_tier": "gold"}}
+	merged := current.Merge(UserProfile{Email: "alice@example.com", Ext: UserExtProfile{"ext_team": "red", "ext_tier": nil}})
+	want := UserProfile{UserId: "u01", Name: "Alice", Email: "alice@example.com", Level: &level, Ext: UserExtProfile{"ext_team": "red"}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("unexpected merge: %+v", merged)
+	}
+	if current.Ext["ext_tier"] != "gold" {
+		t.Error("merge modified the current profile")
+	}
+
+	// A zero level is set explicitly and written.
+	merged = current.Merge(UserProfile{Level: &zero})
+	basic, err := json.Marshal(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(basic) != `{"name":"Alice","level":0}` {
+		t.Errorf("unexpected profile: %s", basic)
+	}
+}
+
+func TestUserProfile_Validate(t *testing.T) {
+	for _, key := range []string{"team", "ext_", "ext_" + string(make([]byte, 29))} {
+		if err := (UserProfile{Ext: UserExtProfile{key: "x"}}).Validate(); err == nil {
+			t.Errorf("expected error for key %q", key)
+		}
+	}
+	if err := (UserProfile{Ext: UserExtProfile{"ext_team": "x"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+	rc := NewRongCloud("key", "secret", REGION_BJ)
+	if _, err := rc.UserProfileUpdate(UserProfile{UserId: "u01"}, "patch"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestRongCloud_UserProfileUpdate(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	level := 2
+	written, err := rc.UserProfileUpdate(UserProfile{
+		UserId:   "u01",
+		Birthday: "20011221",
+		Level:    &level,
+		Ext:      UserExtProfile{"ext_1": "testext"},
+	}, UserProfileMerge)
+	if err != nil {
+		t.Errorf("UserProfileUpdate fail: %s", err)
+		return
+	}
+	profiles, err := rc.UserProfileGet("u01")
+	if err != nil {
+		t.Errorf("UserProfileGet fail: %s", err)
+		return
+	}
+	t.Logf("written: %+v res: %+v", written, profiles)
+}
@@ -0,0 +1,384 @@
+package sdk
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// TAG_MAX_PER_USER Maximum number of tags a user can hold
+	TAG_MAX_PER_USER = 20
+	// TAG_MAX_LENGTH Maximum length of a single tag, in bytes
+	TAG_MAX_LENGTH = 40
+	// TAG_GET_MAX_USERS Maximum number of users per TagGet request
+	TAG_GET_MAX_USERS = 50
+	// TAG_BATCH_MAX_USERS Maximum number of users per TagBatchSet request
+	TAG_BATCH_MAX_USERS = 1000
+
+	// TagOperatorAnd Tags or items must all match
+	TagOperatorAnd = "AND"
+	// TagOperatorOr Any of the tags or items may match
+	TagOperatorOr = "OR"
+)
+
+// TagItem One condition of a push audience tagItems expression, same layout as PushCustomData.Audience.TagItems
+type TagItem struct {
+	Tags          []string `json:"tags"`          // Tags of this condition
+	IsNot         bool     `json:"isNot"`         // Negates the result of this condition
+	TagsOperator  string   `json:"tagsOperator"`  // Relation between Tags: AND or OR, empty means AND
+	ItemsOperator string   `json:"itemsOperator"` // Relation with the result of the previous items: AND or OR, ignored for the first item, empty means AND
+}
+
+// TagSnapshot User tags keyed by user ID, in the same shape as TagResult.Result
+type TagSnapshot map[string][]string
+
+// TagRetagResult Return value of TagRetag
+type TagRetagResult struct {
+	Users    int              // Number of users received from the stream
+	Requests int              // Number of TagBatchSet requests sent
+	Failed   map[string]error // Users whose tags could not be set, keyed by user ID
+}
+
+// TagGetAll Queries the tags of any number of users, splitting the request into TagGet calls of at most 50 users.
+/*
+*@param  userIds: User IDs.
+*
+*@return TagSnapshot, error
+ */
+func (rc *RongCloud) TagGetAll(userIds []string) (TagSnapshot, error) {
+	snapshot := TagSnapshot{}
+	for start := 0; start < len(userIds); start += TAG_GET_MAX_USERS {
+		end := start + TAG_GET_MAX_USERS
+		if end > len(userIds) {
+			end = len(userIds)
+		}
+		res, err := rc.TagGet(userIds[start:end])
+		if err != nil {
+			return snapshot, err
+		}
+		for userId, tags := range res.Result {
+			snapshot[userId] = tags
+		}
+	}
+	return snapshot, nil
+}
+
+// TagAdd Adds tags to a user while keeping the tags the user already has.
+// The current tags are read with TagGet and the merged result is written back with TagSet,
+// so concurrent writers of the same user may overwrite each other.
+/*
+*@param  userId: User ID.
+*@param  tags: Tags to add.
+*
+*@return error
+ */
+func (rc *RongCloud) TagAdd(userId string, tags ...string) error {
+	return rc.tagModify(userId, tags, nil)
+}
+
+// TagRemove Removes tags from a user while keeping the other tags the user has.
+// Like TagAdd this is a read-modify-write over TagGet and TagSet.
+/*
+*@param  userId: User ID.
+*@param  tags: Tags to remove.
+*
+*@return error
+ */
+func (rc *RongCloud) TagRemove(userId string, tags ...string) error {
+	return rc.tagModify(userId, nil, tags)
+}
+
+func (rc *RongCloud) tagModify(userId string, add, remove []string) error {
+	if userId == "" {
+		return RCErrorNew(1002, "Paramer 'userId' is required")
+	}
+	if err := validateTags(add); err != nil {
+		return err
+	}
+	res, err := rc.TagGet([]string{userId})
+	if err != nil {
+		return err
+	}
+	current := res.Result[userId]
+	next := mergeTags(current, add, remove)
+	if len(next) > TAG_MAX_PER_USER {
+		return RCErrorNew(1002, "Paramer 'tags' can not exceed 20 per user")
+	}
+	if equalTags(current, next) {
+		return nil
+	}
+	return rc.TagSet(Tag{UserID: userId, Tags: next})
+}
+
+// TagRetag Replaces the tags of every user received from the stream.
+// Users with identical tag sets are grouped into TagBatchSet requests of at most 1000 users,
+// a group is flushed as soon as it is full and the remaining groups once the stream is closed.
+/*
+*@param  stream: Users and their complete new tag sets. The caller closes the channel when done.
+*
+*@return TagRetagResult, error
+ */
+func (rc *RongCloud) TagRetag(stream <-chan Tag) (TagRetagResult, error) {
+	result := TagRetagResult{Failed: map[string]error{}}
+	groups := map[string]*TagBatch{}
+	var order []string
+
+	flush := func(key string) {
+		batch := groups[key]
+		if batch == nil || len(batch.UserIDs) == 0 {
+			return
+		}
+		result.Requests++
+		if err := rc.TagBatchSet(*batch); err != nil {
+			for _, userId := range batch.UserIDs {
+				result.Failed[userId] = err
+			}
+		}
+		batch.UserIDs = nil
+	}
+
+	for tag := range stream {
+		result.Users++
+		if tag.UserID == "" {
+			continue
+		}
+		if err := validateTags(tag.Tags); err != nil {
+			result.Failed[tag.UserID] = err
+			continue
+		}
+		if len(tag.Tags) > TAG_MAX_PER_USER {
+			result.Failed[tag.UserID] = RCErrorNew(1002, "Paramer 'tags' can not exceed 20 per user")
+			continue
+		}
+		tags := mergeTags(tag.Tags, nil, nil)
+		key := strings.Join(tags, "\x00")
+		batch, ok := groups[key]
+		if !ok {
+			batch = &TagBatch{Tags: tags}
+			groups[key] = batch
+			order = append(order, key)
+		}
+		batch.UserIDs = append(batch.UserIDs, tag.UserID)
+		if len(batch.UserIDs) >= TAG_BATCH_MAX_USERS {
+			flush(key)
+		}
+	}
+	for _, key := range order {
+		flush(key)
+	}
+
+	if len(result.Failed) > 0 {
+		return result, RCErrorNew(1002, "Some users could not be retagged")
+	}
+	return result, nil
+}
+
+// Match Reports the users of the snapshot that a tagItems expression would hit, sorted by user ID.
+/*
+*@param  items: The tagItems expression.
+*
+*@return []string, error
+ */
+func (s TagSnapshot) Match(items []TagItem) ([]string, error) {
+	if err := validateTagItems(items); err != nil {
+		return nil, err
+	}
+	var users []string
+	for userId, tags := range s {
+		if matchTagItems(items, tagSet(tags)) {
+			users = append(users, userId)
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// MatchAudience Reports the users of the snapshot that a push Audience would hit, sorted by user ID.
+// Explicit UserID lists take precedence over tags, as they do on the server. PackageName is not evaluated.
+/*
+*@param  audience: The Audience of a Push or Broadcast.
+*
+*@return []string
+ */
+func (s TagSnapshot) MatchAudience(audience Audience) []string {
+	if audience.IsToAll {
+		return s.users()
+	}
+	if len(audience.UserID) > 0 {
+		var users []string
+		for _, userId := range audience.UserID {
+			if _, ok := s[userId]; ok {
+				users = append(users, userId)
+			}
+		}
+		sort.Strings(users)
+		return users
+	}
+	var users []string
+	for userId, tags := range s {
+		if matchTagAndTagOr(audience.Tag, audience.TagOr, tagSet(tags)) {
+			users = append(users, userId)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// MatchPushCustom Reports the users of the snapshot that a PushCustomObj request would hit, sorted by user ID.
+// When tagItems is present it replaces tag and tag_or, as it does on the server.
+/*
+*@param  data: The PushCustomData of the request.
+*
+*@return []string, error
+ */
+func (s TagSnapshot) MatchPushCustom(data PushCustomData) ([]string, error) {
+	if data.Audience.IsToAll {
+		return s.users(), nil
+	}
+	if len(data.Audience.TagItems) > 0 {
+		items := make([]TagItem, 0, len(data.Audience.TagItems))
+		for _, item := range data.Audience.TagItems {
+			items = append(items, TagItem(item))
+		}
+		return s.Match(items)
+	}
+	return s.MatchAudience(Audience{Tag: data.Audience.Tag, TagOr: data.Audience.TagOr}), nil
+}
+
+func (s TagSnapshot) users() []string {
+	users := make([]string, 0, len(s))
+	for userId := range s {
+		users = append(users, userId)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func matchTagAndTagOr(tag, tagOr []string, set map[string]bool) bool {
+	if len(tag) == 0 && len(tagOr) == 0 {
+		return false
+	}
+	for _, t := range tag {
+		if !set[t] {
+			return false
+		}
+	}
+	if len(tagOr) == 0 {
+		return true
+	}
+	for _, t := range tagOr {
+		if set[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTagItems evaluates items from left to right, each item combined with the result so far.
+func matchTagItems(items []TagItem, set map[string]bool) bool {
+	var result bool
+	for i, item := range items {
+		hit := matchTagItem(item, set)
+		if i == 0 {
+			result = hit
+			continue
+		}
+		if strings.EqualFold(item.ItemsOperator, TagOperatorOr) {
+			result = result || hit
+		} else {
+			result = result && hit
+		}
+	}
+	return result
+}
+
+func matchTagItem(item TagItem, set map[string]bool) bool {
+	or := strings.EqualFold(item.TagsOperator, TagOperatorOr)
+	hit := !or
+	for _, t := range item.Tags {
+		if or && set[t] {
+			hit = true
+			break
+		}
+		if !or && !set[t] {
+			hit = false
+			break
+		}
+	}
+	if len(item.Tags) == 0 {
+		hit = false
+	}
+	if item.IsNot {
+		return !hit
+	}
+	return hit
+}
+
+func validateTagItems(items []TagItem) error {
+	if len(items) == 0 {
+		return RCErrorNew(1002, "Paramer 'tagItems' is required")
+	}
+	for _, item := range items {
+		if !validTagOperator(item.TagsOperator) {
+			return RCErrorNew(1002, "Paramer 'tagsOperator' must be AND or OR")
+		}
+		if !validTagOperator(item.ItemsOperator) {
+			return RCErrorNew(1002, "Paramer 'itemsOperator' must be AND or OR")
+		}
+	}
+	return nil
+}
+
+func validTagOperator(op string) bool {
+	return op == "" || strings.EqualFold(op, TagOperatorAnd) || strings.EqualFold(op, TagOperatorOr)
+}
+
+func validateTags(tags []string) error {
+	for _, t := range tags {
+		if t == "" {
+			return RCErrorNew(1002, "Paramer 'tags' can not contain empty tag")
+		}
+		if len(t) > TAG_MAX_LENGTH {
+			return RCErrorNew(1002, "Paramer 'tags' can not exceed 40 bytes per tag")
+		}
+	}
+	return nil
+}
+
+// mergeTags returns the sorted, deduplicated result of current + add - remove.
+func mergeTags(current, add, remove []string) []string {
+	set := tagSet(current)
+	for _, t := range add {
+		set[t] = true
+	}
+	for _, t := range remove {
+		delete(set, t)
+	}
+	tags := make([]string, 0, len(set))
+	for t := range set {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := tagSet(a)
+	for _, t := range b {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		set[t] = true
+	}
+	return set
+}
@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+var testTagSnapshot = TagSnapshot{
+	"u1": {"female", "young", "Beijing"},
+	"u2": {"male", "Shanghai"},
+	"u3": {"female", "Shanghai", "20200408"},
+	"u4": {"guangdong"},
+}
+
+func TestTagSnapshot_Match(t *testing.T) {
+	users, err := testTagSnapshot.Match([]TagItem{
+		{Tags: []string{"female", "male"}, TagsOperator: "OR"},
+		{Tags: []string{"20200408"}, IsNot: true, TagsOperator: "OR", ItemsOperator: "AND"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, []string{"u1", "u2"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	users, err = testTagSnapshot.Match([]TagItem{
+		{Tags: []string{"female", "Shanghai"}, TagsOperator: "AND"},
+		{Tags: []string{"guangdong"}, ItemsOperator: "or"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(users, []string{"u3", "u4"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	if _, err = testTagSnapshot.Match([]TagItem{{Tags: []string{"a"}, TagsOperator: "XOR"}}); err == nil {
+		t.Error("expected invalid operator error")
+	}
+}
+
+func TestTagSnapshot_MatchAudience(t *testing.T) {
+	users := testTagSnapshot.MatchAudience(Audience{Tag: []string{"female"}, TagOr: []string{"Beijing", "guangdong"}})
+	if !reflect.DeepEqual(users, []string{"u1"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+	users = testTagSnapshot.MatchAudience(Audience{Tag: []string{"female"}, UserID: []string{"u2", "u9"}})
+	if !reflect.DeepEqual(users, []string{"u2"}) {
+		t.Errorf("unexpected users: %v", users)
+	}
+	users = testTagSnapshot.MatchAudience(Audience{IsToAll: true})
+	if len(users) != len(testTagSnapshot) {
+		t.Errorf("unexpected users: %v", users)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tags := mergeTags([]string{"b", "a"}, []string{"c", "a"}, []string{"b"})
+	if !reflect.DeepEqual(tags, []string{"a", "c"}) {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
+
+func TestRongCloud_TagAdd(t *testing.T) {
+	rc := NewRongCloud(
+		os.Getenv("APP_KEY"),
+		os.Getenv("APP_SECRET"),
+		REGION_BJ,
+	)
+	if err := rc.TagAdd("u01", "female", "Beijing"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := rc.TagRemove("u01", "Beijing"); err != nil {
+		t.Error(err)
+		return
+	}
+	t.Log("success")
+}